@@ -0,0 +1,132 @@
+// Package analytics computes statistics over the monthly rate observations, such as
+// rolling-window statistics and exponentially weighted moving averages.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// Statistics accepted by Compute.
+const (
+	StatMean   = "mean"
+	StatStdDev = "stddev"
+	StatMin    = "min"
+	StatMax    = "max"
+	StatEWMA   = "ewma"
+)
+
+// Stats lists the supported statistics.
+var Stats = []string{StatMean, StatStdDev, StatMin, StatMax, StatEWMA}
+
+// Compute returns the statistic over observations sorted by date. window is the number of
+// observations per window; for ewma it sets the span, giving alpha = 2/(window+1), unless
+// alpha is non-zero.
+func Compute(observations []dto.Observation, stat string, window int, alpha float64) ([]dto.RollingPoint, error) {
+	if stat == StatEWMA {
+		if alpha == 0 {
+			alpha = SpanAlpha(window)
+		}
+		return EWMA(observations, alpha)
+	}
+	return Rolling(observations, stat, window)
+}
+
+// Rolling computes mean, stddev, min or max over a window of the last window observations
+// ending at each observation. Observations before the first full window are omitted.
+// stddev is the sample standard deviation (n-1), and 0 for a window of 1.
+func Rolling(observations []dto.Observation, stat string, window int) ([]dto.RollingPoint, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("window must be at least 1, got %d", window)
+	}
+	var fn func([]float64) float64
+	switch stat {
+	case StatMean:
+		fn = mean
+	case StatStdDev:
+		fn = stddev
+	case StatMin:
+		fn = minimum
+	case StatMax:
+		fn = maximum
+	default:
+		return nil, fmt.Errorf("stat must be one of %s, got %q", strings.Join(Stats, ", "), stat)
+	}
+
+	rates := make([]float64, len(observations))
+	for i, o := range observations {
+		rates[i] = o.Value
+	}
+	points := make([]dto.RollingPoint, 0, max(len(observations)-window+1, 0))
+	for end := window; end <= len(rates); end++ {
+		o := observations[end-1]
+		points = append(points, dto.RollingPoint{Date: o.Date.Format("2006-01-02"), Rate: o.Value, Value: fn(rates[end-window : end])})
+	}
+	return points, nil
+}
+
+// EWMA computes the exponentially weighted moving average with smoothing factor alpha
+// in (0, 1], starting from the first observation:
+//
+//	s[0] = x[0], s[t] = alpha*x[t] + (1-alpha)*s[t-1]
+func EWMA(observations []dto.Observation, alpha float64) ([]dto.RollingPoint, error) {
+	if alpha <= 0 || alpha > 1 || math.IsNaN(alpha) {
+		return nil, fmt.Errorf("alpha must be greater than 0 and at most 1, got %v", alpha)
+	}
+	points := make([]dto.RollingPoint, 0, len(observations))
+	var s float64
+	for i, o := range observations {
+		if i == 0 {
+			s = o.Value
+		} else {
+			s = alpha*o.Value + (1-alpha)*s
+		}
+		points = append(points, dto.RollingPoint{Date: o.Date.Format("2006-01-02"), Rate: o.Value, Value: s})
+	}
+	return points, nil
+}
+
+// SpanAlpha returns the EWMA smoothing factor for a span of n observations, 2/(n+1),
+// whose center of mass matches a simple moving average over n observations.
+func SpanAlpha(n int) float64 {
+	return 2 / (float64(n) + 1)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var squares float64
+	for _, v := range values {
+		squares += (v - m) * (v - m)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+func minimum(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+func maximum(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,157 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// series returns monthly observations starting in January 2024.
+func series(values ...float64) []dto.Observation {
+	observations := make([]dto.Observation, len(values))
+	for i, v := range values {
+		observations[i] = dto.Observation{Date: time.Date(2024, time.January+time.Month(i), 1, 0, 0, 0, 0, time.UTC), Value: v}
+	}
+	return observations
+}
+
+// checkPoints compares the dates and values of points with want, one per trailing
+// observation of obs.
+func checkPoints(t *testing.T, obs []dto.Observation, points []dto.RollingPoint, want []float64) {
+	t.Helper()
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	offset := len(obs) - len(want)
+	for i, p := range points {
+		o := obs[offset+i]
+		if p.Date != o.Date.Format("2006-01-02") || p.Rate != o.Value {
+			t.Errorf("point %d is for %s (rate %g), want %s (rate %g)", i, p.Date, p.Rate, o.Date.Format("2006-01-02"), o.Value)
+		}
+		if math.Abs(p.Value-want[i]) > 1e-9 {
+			t.Errorf("point %d (%s) = %g, want %g", i, p.Date, p.Value, want[i])
+		}
+	}
+}
+
+func TestRolling(t *testing.T) {
+	obs := series(1, 2, 4, 8)
+	tests := []struct {
+		name   string
+		stat   string
+		window int
+		want   []float64
+	}{
+		{"mean", StatMean, 2, []float64{1.5, 3, 6}},
+		{"mean over everything", StatMean, 4, []float64{3.75}},
+		{"mean of one", StatMean, 1, []float64{1, 2, 4, 8}},
+		{"sample stddev", StatStdDev, 2, []float64{math.Sqrt2 / 2, math.Sqrt2, 2 * math.Sqrt2}},
+		{"stddev of three", StatStdDev, 3, []float64{math.Sqrt(7.0 / 3), math.Sqrt(28.0 / 3)}},
+		{"stddev of one is 0", StatStdDev, 1, []float64{0, 0, 0, 0}},
+		{"min", StatMin, 3, []float64{1, 2}},
+		{"max", StatMax, 3, []float64{4, 8}},
+		{"window longer than the series", StatMean, 5, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := Rolling(obs, tt.stat, tt.window)
+			if err != nil {
+				t.Fatalf("Rolling() error = %v", err)
+			}
+			checkPoints(t, obs, points, tt.want)
+		})
+	}
+}
+
+func TestRollingErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stat   string
+		window int
+	}{
+		{"zero window", StatMean, 0},
+		{"negative window", StatMax, -3},
+		{"unknown stat", "median", 3},
+		{"ewma is not a window stat", StatEWMA, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Rolling(series(1, 2, 3), tt.stat, tt.window); err == nil {
+				t.Errorf("Rolling(%q, %d) succeeded, want an error", tt.stat, tt.window)
+			}
+		})
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	obs := series(1, 2, 4, 8)
+	tests := []struct {
+		name    string
+		alpha   float64
+		want    []float64
+		wantErr bool
+	}{
+		{name: "half", alpha: 0.5, want: []float64{1, 1.5, 2.75, 5.375}},
+		{name: "quarter", alpha: 0.25, want: []float64{1, 1.25, 1.9375, 3.453125}},
+		{name: "alpha 1 follows the series", alpha: 1, want: []float64{1, 2, 4, 8}},
+		{name: "zero alpha", alpha: 0, wantErr: true},
+		{name: "negative alpha", alpha: -0.5, wantErr: true},
+		{name: "alpha above 1", alpha: 1.5, wantErr: true},
+		{name: "NaN alpha", alpha: math.NaN(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := EWMA(obs, tt.alpha)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EWMA(%g) succeeded, want an error", tt.alpha)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EWMA(%g) error = %v", tt.alpha, err)
+			}
+			checkPoints(t, obs, points, tt.want)
+		})
+	}
+}
+
+func TestComputeEWMASpan(t *testing.T) {
+	obs := series(1, 2, 4, 8)
+	tests := []struct {
+		name   string
+		window int
+		alpha  float64
+		want   []float64
+	}{
+		{"span 3 gives alpha 0.5", 3, 0, []float64{1, 1.5, 2.75, 5.375}},
+		{"alpha overrides the span", 3, 1, []float64{1, 2, 4, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := Compute(obs, StatEWMA, tt.window, tt.alpha)
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			checkPoints(t, obs, points, tt.want)
+		})
+	}
+}
+
+func TestSpanAlpha(t *testing.T) {
+	tests := []struct {
+		n    int
+		want float64
+	}{
+		{1, 1},
+		{3, 0.5},
+		{12, 2.0 / 13},
+	}
+	for _, tt := range tests {
+		if got := SpanAlpha(tt.n); got != tt.want {
+			t.Errorf("SpanAlpha(%d) = %g, want %g", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package appmetrics defines this application's own metrics: the job queue, database,
+// upstream data source, federal funds data and configuration reloads. They are added to a
+// generic metrics.Metrics instance, so they share its namespace, registry and dashboard.
+package appmetrics
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"federal-funds-rate-metrics-ByYear/metrics"
+)
+
+// Metrics holds the application collectors added to one metrics.Metrics instance.
+// Create it with New.
+type Metrics struct {
+	latestObs atomic.Int64 // Unix time of the latest observation, 0 if unknown.
+
+	// Job queue metrics.
+	QueueLength       prometheus.Gauge
+	ThroughputCounter prometheus.Counter
+	JobsTotal         *prometheus.CounterVec
+	JobDuration       *prometheus.HistogramVec
+
+	// Database metrics.
+	DBQueryDuration   *prometheus.HistogramVec
+	DBQueryErrors     *prometheus.CounterVec
+	DBOpenConnections prometheus.Gauge
+
+	// Upstream (outbound) HTTP metrics, labeled by the data source name.
+	UpstreamDuration      *prometheus.HistogramVec
+	UpstreamStatusCounter *prometheus.CounterVec
+	UpstreamBytesReceived *prometheus.CounterVec
+	UpstreamThrottled     *prometheus.CounterVec
+	UpstreamObservations  *prometheus.HistogramVec
+
+	// Configuration reload metrics.
+	ConfigReloads    *prometheus.CounterVec
+	ConfigLastReload prometheus.Gauge
+
+	// Federal funds data metrics.
+	LatestRate            prometheus.Gauge
+	LatestObservationTime prometheus.Gauge
+	DataStaleness         prometheus.GaugeFunc
+	YearsStored           prometheus.Gauge
+	LastRefreshTime       prometheus.Gauge
+	LastRefreshSuccess    prometheus.Gauge
+}
+
+// New adds the application collectors and their dashboard rows to m.
+func New(m *metrics.Metrics) *Metrics {
+	a := &Metrics{
+		QueueLength:       m.NewGauge("queue_length", "Length of the processing queue"),
+		ThroughputCounter: m.NewCounter("throughput_total", "Total number of processed items (throughput)"),
+		JobsTotal:         m.NewCounterVec("jobs_total", "Total number of finished background jobs by kind and status", "kind", "status"),
+		JobDuration:       m.NewHistogramVec("job_duration_seconds", "Duration of background jobs", nil, "kind"),
+
+		DBQueryDuration:   m.NewHistogramVec("db_query_duration_seconds", "Duration of database queries by operation and outcome", nil, "operation", "outcome"),
+		DBQueryErrors:     m.NewCounterVec("db_query_errors_total", "Total number of failed database queries by operation and SQLSTATE class", "operation", "sqlstate_class"),
+		DBOpenConnections: m.NewGauge("db_open_connections", "Number of open database connections"),
+
+		UpstreamDuration:      m.NewHistogramVec("upstream_request_duration_seconds", "Histogram of response time for outbound requests to data sources", nil, "source"),
+		UpstreamStatusCounter: m.NewCounterVec("upstream_responses_total", "Total number of outbound responses by status code (\"error\" for transport failures)", "source", "code"),
+		UpstreamBytesReceived: m.NewCounterVec("upstream_received_bytes_total", "Total number of response body bytes received from data sources", "source"),
+		UpstreamThrottled:     m.NewCounterVec("upstream_throttled_total", "Total number of responses where the data source signalled rate limiting", "source"),
+		UpstreamObservations:  m.NewHistogramVec("upstream_observations_per_fetch", "Number of observations parsed from each successful fetch", prometheus.ExponentialBuckets(10, 2, 10), "source"),
+
+		ConfigReloads:    m.NewCounterVec("config_reloads_total", "Total number of configuration reloads by trigger and result", "trigger", "result"),
+		ConfigLastReload: m.NewGauge("config_last_reload_timestamp_seconds", "Unix time of the last configuration reload attempt"),
+
+		LatestRate:            m.NewGauge("federal_funds_latest_rate_percent", "Most recent observed federal funds rate"),
+		LatestObservationTime: m.NewGauge("federal_funds_latest_observation_timestamp_seconds", "Unix time of the most recent observation (0 if unknown)"),
+		YearsStored:           m.NewGauge("federal_funds_years_stored", "Number of years with stored insights"),
+		LastRefreshTime:       m.NewGauge("federal_funds_last_refresh_timestamp_seconds", "Unix time of the last refresh attempt"),
+		LastRefreshSuccess:    m.NewGauge("federal_funds_last_refresh_success", "1 if the last refresh succeeded, 0 if it failed"),
+	}
+	a.DataStaleness = m.NewGaugeFunc("federal_funds_data_staleness_seconds", "Seconds since the most recent observation (0 if unknown)", func() float64 {
+		latest := a.latestObs.Load()
+		if latest == 0 {
+			return 0
+		}
+		return time.Since(time.Unix(latest, 0)).Seconds()
+	})
+
+	m.AddDashboardSection("Jobs", "queue_", "throughput_", "job")
+	m.AddDashboardSection("Database", "db_")
+	m.AddDashboardSection("Upstream", "upstream_")
+	m.AddDashboardSection("Federal funds data", "federal_funds_")
+	m.AddDashboardSection("Configuration", "config_")
+	return a
+}
+
+// RecordDBQuery records a database query duration under its operation name and outcome.
+func (a *Metrics) RecordDBQuery(ctx context.Context, operation, outcome string, duration time.Duration) {
+	metrics.ObserveWithTrace(ctx, a.DBQueryDuration.WithLabelValues(operation, outcome), duration.Seconds())
+}
+
+// RecordDBError counts a failed database query by operation and SQLSTATE class.
+func (a *Metrics) RecordDBError(operation, sqlStateClass string) {
+	a.DBQueryErrors.WithLabelValues(operation, sqlStateClass).Inc()
+}
+
+// UpdateDBConnections sets the current number of open database connections.
+func (a *Metrics) UpdateDBConnections(conns int) {
+	a.DBOpenConnections.Set(float64(conns))
+}
+
+// SetQueueLength sets the number of jobs waiting to be processed.
+func (a *Metrics) SetQueueLength(n int) {
+	a.QueueLength.Set(float64(n))
+}
+
+// RecordJob records a finished background job and counts it towards throughput.
+func (a *Metrics) RecordJob(kind, status string, duration time.Duration) {
+	a.ThroughputCounter.Inc()
+	a.JobsTotal.WithLabelValues(kind, status).Inc()
+	a.JobDuration.WithLabelValues(kind).Observe(duration.Seconds())
+}
+
+// RecordUpstreamRequest records a single outbound request to a data source.
+// A code of 0 means the request failed before a response was received.
+func (a *Metrics) RecordUpstreamRequest(ctx context.Context, source string, start time.Time, code int, bytes int) {
+	metrics.ObserveWithTrace(ctx, a.UpstreamDuration.WithLabelValues(source), time.Since(start).Seconds())
+	label := "error"
+	if code > 0 {
+		label = strconv.Itoa(code)
+	}
+	a.UpstreamStatusCounter.WithLabelValues(source, label).Inc()
+	if bytes > 0 {
+		a.UpstreamBytesReceived.WithLabelValues(source).Add(float64(bytes))
+	}
+}
+
+// RecordUpstreamThrottle records a rate-limiting response from a data source.
+func (a *Metrics) RecordUpstreamThrottle(source string) {
+	a.UpstreamThrottled.WithLabelValues(source).Inc()
+}
+
+// RecordUpstreamObservations records how many observations a fetch produced.
+func (a *Metrics) RecordUpstreamObservations(source string, count int) {
+	a.UpstreamObservations.WithLabelValues(source).Observe(float64(count))
+}
+
+// SetLatestObservation records the most recent observed rate and its date.
+func (a *Metrics) SetLatestObservation(date time.Time, rate float64) {
+	a.latestObs.Store(date.Unix())
+	a.LatestObservationTime.Set(float64(date.Unix()))
+	a.LatestRate.Set(rate)
+}
+
+// SetYearsStored records how many years have stored insights.
+func (a *Metrics) SetYearsStored(n int) {
+	a.YearsStored.Set(float64(n))
+}
+
+// RecordRefresh records the outcome of a data refresh.
+func (a *Metrics) RecordRefresh(success bool) {
+	a.LastRefreshTime.Set(float64(time.Now().Unix()))
+	if success {
+		a.LastRefreshSuccess.Set(1)
+	} else {
+		a.LastRefreshSuccess.Set(0)
+	}
+}
+
+// RecordConfigReload records a configuration reload attempt. trigger is what started it
+// ("signal" or "file") and result its outcome ("applied", "unchanged", "rejected" or "failed").
+func (a *Metrics) RecordConfigReload(trigger, result string) {
+	a.ConfigReloads.WithLabelValues(trigger, result).Inc()
+	a.ConfigLastReload.Set(float64(time.Now().Unix()))
+}
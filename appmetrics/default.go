@@ -0,0 +1,77 @@
+package appmetrics
+
+import (
+	"context"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/metrics"
+)
+
+// defaultMetrics adds the application metrics to metrics.Default() and backs the
+// package-level functions.
+var defaultMetrics = New(metrics.Default())
+
+// Default returns the application metrics added to metrics.Default().
+func Default() *Metrics {
+	return defaultMetrics
+}
+
+// RecordDBQuery records a database query duration on the default instance.
+func RecordDBQuery(ctx context.Context, operation, outcome string, duration time.Duration) {
+	defaultMetrics.RecordDBQuery(ctx, operation, outcome, duration)
+}
+
+// RecordDBError counts a failed database query on the default instance.
+func RecordDBError(operation, sqlStateClass string) {
+	defaultMetrics.RecordDBError(operation, sqlStateClass)
+}
+
+// UpdateDBConnections sets the current number of open database connections on the default instance.
+func UpdateDBConnections(conns int) {
+	defaultMetrics.UpdateDBConnections(conns)
+}
+
+// SetQueueLength sets the job queue depth on the default instance.
+func SetQueueLength(n int) {
+	defaultMetrics.SetQueueLength(n)
+}
+
+// RecordJob records a finished background job on the default instance.
+func RecordJob(kind, status string, duration time.Duration) {
+	defaultMetrics.RecordJob(kind, status, duration)
+}
+
+// RecordUpstreamRequest records an outbound request on the default instance.
+func RecordUpstreamRequest(ctx context.Context, source string, start time.Time, code int, bytes int) {
+	defaultMetrics.RecordUpstreamRequest(ctx, source, start, code, bytes)
+}
+
+// RecordUpstreamThrottle records a rate-limiting response on the default instance.
+func RecordUpstreamThrottle(source string) {
+	defaultMetrics.RecordUpstreamThrottle(source)
+}
+
+// RecordUpstreamObservations records how many observations a fetch produced on the default instance.
+func RecordUpstreamObservations(source string, count int) {
+	defaultMetrics.RecordUpstreamObservations(source, count)
+}
+
+// SetLatestObservation records the most recent observed rate on the default instance.
+func SetLatestObservation(date time.Time, rate float64) {
+	defaultMetrics.SetLatestObservation(date, rate)
+}
+
+// SetYearsStored records how many years have stored insights on the default instance.
+func SetYearsStored(n int) {
+	defaultMetrics.SetYearsStored(n)
+}
+
+// RecordRefresh records the outcome of a data refresh on the default instance.
+func RecordRefresh(success bool) {
+	defaultMetrics.RecordRefresh(success)
+}
+
+// RecordConfigReload records a configuration reload attempt on the default instance.
+func RecordConfigReload(trigger, result string) {
+	defaultMetrics.RecordConfigReload(trigger, result)
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/config"
+	"federal-funds-rate-metrics-ByYear/db"
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/export"
+	"federal-funds-rate-metrics-ByYear/health"
+	"federal-funds-rate-metrics-ByYear/importer"
+	"federal-funds-rate-metrics-ByYear/logging"
+	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// loadCommandConfig loads the configuration for a one-off command, parsing the command's
+// own flags along with the configuration flags, and sets up logging.
+func loadCommandConfig(flags *flag.FlagSet, args []string) (*config.Config, error) {
+	appConfig, err := config.LoadFlagSet(flags, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.Setup(appConfig.LogFormat, appConfig.LogLevel); err != nil {
+		return nil, fmt.Errorf("setting up logging: %v", err)
+	}
+	return appConfig, nil
+}
+
+// connect opens the database pool and, if requireSchema is set, checks that all
+// migrations have been applied. Callers must call db.Close.
+func connect(ctx context.Context, appConfig *config.Config, requireSchema bool) error {
+	if err := db.Connect(appConfig.DatabaseURL, appConfig.DBMaxConns, appConfig.DBMinConns); err != nil {
+		return err
+	}
+	if !requireSchema {
+		return nil
+	}
+	if err := health.Migrations()(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("%v; run the migrate command first", err)
+	}
+	return nil
+}
+
+// interruptible returns a context cancelled on SIGINT or SIGTERM.
+func interruptible() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// noArguments rejects positional arguments left after flag parsing.
+func noArguments(flags *flag.FlagSet) error {
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	return nil
+}
+
+// fetch fetches observations from the configured source once, stores them and recomputes insights.
+func fetch(args []string) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	upstream := source.NewClient(appConfig.APIKey, upstreamOptions(appConfig))
+	data, err := upstream.FetchFederalFundsRate(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching from %s: %v", appConfig.Source, err)
+	}
+	insights, err := services.RefreshInsights(ctx, data, source.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Fetched %d observations from %s and refreshed insights for %d years\n", len(data.Data), appConfig.Source, len(insights))
+	return nil
+}
+
+// importFiles loads observations from local files into the database and recomputes insights,
+// for environments without access to the upstream source.
+func importFiles(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", importer.FormatAuto, "File format: auto, "+strings.Join(importer.Formats, ", "))
+	dryRun := flags.Bool("dry-run", false, "Read and check the files without writing to the database")
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: import [--format FORMAT] [--dry-run] [flags] FILE...")
+	}
+
+	var observations []dto.Observation
+	for _, path := range flags.Args() {
+		read, err := importer.ReadFile(path, *format)
+		if err != nil {
+			return err
+		}
+		first, last := read[0].Date, read[0].Date
+		for _, o := range read {
+			if o.Date.Before(first) {
+				first = o.Date
+			}
+			if o.Date.After(last) {
+				last = o.Date
+			}
+		}
+		fmt.Printf("%s: %d observations from %s to %s\n", path, len(read), first.Format("2006-01-02"), last.Format("2006-01-02"))
+		observations = append(observations, read...)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	insights, err := services.ImportObservations(ctx, observations)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d observations and recomputed insights for %d years\n", len(observations), len(insights))
+	return nil
+}
+
+// migrate applies pending database migrations.
+func migrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, false); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	applied, err := db.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d migration(s); schema is at version %d\n", applied, version)
+	return nil
+}
+
+// Data sets written by the export command.
+const (
+	exportObservations = "observations"
+	exportInsights     = "insights"
+)
+
+// exportData writes stored observations or insights as CSV or JSON, optionally limited to a date range.
+func exportData(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	data := flags.String("data", exportObservations, "Data to export: observations or insights")
+	format := flags.String("format", export.FormatCSV, "Output format: csv or json")
+	from := flags.String("from", "", "First date to include (YYYY, YYYY-MM or YYYY-MM-DD)")
+	to := flags.String("to", "", "Last date to include (YYYY, YYYY-MM or YYYY-MM-DD)")
+	output := flags.String("output", "-", "File to write, - for standard output")
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+	if *data != exportObservations && *data != exportInsights {
+		return fmt.Errorf("--data must be observations or insights, got %q", *data)
+	}
+	if *format != export.FormatCSV && *format != export.FormatJSON {
+		return fmt.Errorf("--format must be csv or json, got %q", *format)
+	}
+	start, err := parseDateBound(*from, false)
+	if err != nil {
+		return fmt.Errorf("--from: %v", err)
+	}
+	end, err := parseDateBound(*to, true)
+	if err != nil {
+		return fmt.Errorf("--to: %v", err)
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("--to %s is before --from %s", *to, *from)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if *output != "-" {
+		file, err = os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %v", err)
+		}
+		w = file
+	}
+
+	var rows int
+	if *data == exportObservations {
+		rows, err = exportObservationRows(ctx, w, *format, start, end)
+	} else {
+		rows, err = exportInsightRows(ctx, w, *format, start, end)
+	}
+	if file != nil {
+		// Close reports write errors the OS deferred, such as a full disk.
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("writing output file: %v", closeErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if *output != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %d %s to %s\n", rows, *data, *output)
+	}
+	return nil
+}
+
+// exportObservationRows writes the observations dated between start and end (either may be zero).
+func exportObservationRows(ctx context.Context, w io.Writer, format string, start, end time.Time) (int, error) {
+	observations, err := services.GetObservations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	selected := make([]dto.Observation, 0, len(observations))
+	for _, o := range observations {
+		if (start.IsZero() || !o.Date.Before(start)) && (end.IsZero() || !o.Date.After(end)) {
+			selected = append(selected, o)
+		}
+	}
+
+	return len(selected), export.WriteObservations(w, format, selected)
+}
+
+// exportInsightRows writes the insights for the years between start and end (either may be zero).
+func exportInsightRows(ctx context.Context, w io.Writer, format string, start, end time.Time) (int, error) {
+	insights, err := services.GetAllYearsData(ctx)
+	if err != nil {
+		return 0, err
+	}
+	selected := make([]dto.YearlyInsight, 0, len(insights))
+	for _, insight := range insights {
+		if (start.IsZero() || insight.Year >= start.Year()) && (end.IsZero() || insight.Year <= end.Year()) {
+			selected = append(selected, insight)
+		}
+	}
+
+	return len(selected), export.WriteInsights(w, format, selected)
+}
+
+// snapshot writes a snapshot archive of the observations and insights tables, like the export job.
+func snapshot(args []string) error {
+	flags := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	path, manifest, err := export.Snapshot(ctx, exportOptions(appConfig))
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	for _, t := range manifest.Tables {
+		fmt.Printf("  %-14s %6d rows  %s\n", t.File, t.Rows, t.SHA256)
+	}
+	return nil
+}
+
+// parseDateBound parses a YYYY, YYYY-MM or YYYY-MM-DD date. An upper bound covers the
+// whole year or month given, so --to 2020 includes December 2020. Empty means unbounded.
+func parseDateBound(value string, upper bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []struct {
+		layout string
+		years  int
+		months int
+	}{
+		{"2006", 1, 0},
+		{"2006-01", 0, 1},
+		{"2006-01-02", 0, 0},
+	} {
+		t, err := time.Parse(layout.layout, value)
+		if err != nil {
+			continue
+		}
+		if upper && (layout.years > 0 || layout.months > 0) {
+			t = t.AddDate(layout.years, layout.months, -1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("date must look like YYYY, YYYY-MM or YYYY-MM-DD, got %q", value)
+}
+
+// users runs the "users create", "users list" and "users delete" commands.
+func users(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: users create --name NAME --email EMAIL | list | delete [flags] EMAIL")
+	}
+	switch args[0] {
+	case "create":
+		return createUser(args[1:])
+	case "list":
+		return listUsers(args[1:])
+	case "delete":
+		return deleteUser(args[1:])
+	default:
+		return fmt.Errorf("unknown users command %q; use create, list or delete", args[0])
+	}
+}
+
+// createUser adds a user, validated like POST /create.
+func createUser(args []string) error {
+	flags := flag.NewFlagSet("users create", flag.ContinueOnError)
+	name := flags.String("name", "", "Name of the user (required)")
+	email := flags.String("email", "", "Email address of the user (required)")
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+	user := dto.UserDto{Name: *name, Email: *email}
+	if err := validator.New().Struct(user); err != nil {
+		return fmt.Errorf("invalid user: %v", err)
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	created, err := services.CreateUser(ctx, &user)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created user %s <%s>\n", created.Name, created.Email)
+	return nil
+}
+
+// listUsers prints every user as a table.
+func listUsers(args []string) error {
+	flags := flag.NewFlagSet("users list", flag.ContinueOnError)
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := noArguments(flags); err != nil {
+		return err
+	}
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	list, err := services.GetAllUsers(ctx)
+	if err != nil {
+		return err
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tNAME\tEMAIL")
+	for _, u := range list {
+		fmt.Fprintf(table, "%d\t%s\t%s\n", u.ID, u.Name, u.Email)
+	}
+	return table.Flush()
+}
+
+// deleteUser removes the user with the email given as the only argument.
+func deleteUser(args []string) error {
+	flags := flag.NewFlagSet("users delete", flag.ContinueOnError)
+	appConfig, err := loadCommandConfig(flags, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: users delete [flags] EMAIL")
+	}
+	email := flags.Arg(0)
+
+	ctx, stop := interruptible()
+	defer stop()
+	if err := connect(ctx, appConfig, true); err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := services.DeleteUser(ctx, email); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted user %s\n", email)
+	return nil
+}
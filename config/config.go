@@ -1,41 +1,579 @@
 package config
 
 import (
-	"log"
-	"os"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/redact"
 
 	"github.com/joho/godotenv"
 )
 
+// SLO declares service level objectives for one route pattern
+type SLO struct {
+	Route              string
+	AvailabilityTarget float64       // Percentage of non-5xx responses, 0 if not set
+	LatencyThreshold   time.Duration // Requests at or under this are fast enough
+	LatencyTarget      float64       // Percentage of requests within LatencyThreshold, 0 if not set
+}
+
 // Config holds the configuration values
 type Config struct {
 	APIKey      string
 	DatabaseURL string
+
+	// Bearer token for the /admin endpoints; without one they only answer loopback clients
+	AdminToken string
+
+	// HTTP listen port
+	Port int
+
+	// Where observations are fetched from
+	Source string
+
+	// Database pool size limits (0 keeps the pgx defaults)
+	DBMaxConns int
+	DBMinConns int
+
+	// Upstream HTTP client settings
+	UpstreamTimeout          time.Duration
+	UpstreamMaxRetries       int
+	UpstreamBaseBackoff      time.Duration
+	UpstreamMaxBackoff       time.Duration
+	UpstreamBreakerThreshold int
+	UpstreamBreakerCooldown  time.Duration
+	UpstreamRateLimit        int
+
+	// How often process resource metrics are sampled
+	MetricsInterval time.Duration
+
+	// File where uptime heartbeats are persisted between runs
+	AvailabilityFile string
+
+	// Background job queue and refresh schedule
+	JobWorkers      int
+	JobQueueSize    int
+	RefreshInterval time.Duration // 0 disables scheduled refreshes
+
+	// Snapshot archives: directory, table format, schedule (0 disables) and how many to keep (0 keeps all)
+	ExportDir      string
+	ExportFormat   string
+	ExportInterval time.Duration
+	ExportKeep     int
+
+	// Tracing exporter ("otlp", "stdout", "local" or "none") and OTLP collector URL
+	TracingExporter string
+	TracingEndpoint string
+
+	// Service level objectives and how often they are evaluated
+	SLOs        []SLO
+	SLOInterval time.Duration
+
+	// Log output format ("text" or "json") and minimum level
+	LogFormat string
+	LogLevel  string
+
+	// HTTP server timeouts and how long shutdown waits for in-flight requests
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+
+	// Health checks: per-check timeout, the oldest acceptable refresh and the longest a job
+	// may run (0 disables those checks)
+	HealthCheckTimeout  time.Duration
+	HealthMaxRefreshAge time.Duration
+	HealthMaxJobRuntime time.Duration
+
+	// File the configuration was read from, empty if none
+	File string
+
+	// .env file read as its own layer, empty if there was none
+	dotEnv string
+
+	// Raw value of each setting and where it came from, by key
+	values  map[string]string
+	origins map[string]string
+
+	// Files secret settings were read from, by key
+	secretFiles map[string]string
+}
+
+// Origins of a setting value, from lowest to highest precedence.
+const (
+	OriginDefault = "default"
+	OriginFile    = "file"
+	OriginDotEnv  = "dotenv"
+	OriginEnv     = "env"
+	OriginFlag    = "flag"
+)
+
+// dotEnvFile is read on every load, so a reload picks up its changes.
+const dotEnvFile = ".env"
+
+// Sources that can be selected with the "source" setting.
+var Sources = []string{"alphavantage"}
+
+// secretSettings can also be read from a file, as mounted by Docker and Kubernetes secrets:
+// <ENV>_FILE in the environment, --<flag>-file on the command line or <key>_file in a config file.
+// Their values are registered for redaction and never shown in full.
+var secretSettings = map[string]bool{"api_key": true, "database_url": true, "admin.token": true}
+
+// setting is one configuration value, settable from a file, the environment or a flag.
+type setting struct {
+	key   string // Name in config files, e.g. "upstream.timeout".
+	env   string // Environment variable, e.g. "UPSTREAM_TIMEOUT".
+	def   string // Default value, empty for none.
+	usage string
+	apply func(c *Config, value string) error
+}
+
+// flagName is the command-line flag for the setting, e.g. "upstream-timeout".
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// settings lists every configuration value with its default.
+var settings = []setting{
+	{"api_key", "API_KEY", "", "Alpha Vantage API key (required)", str(func(c *Config) *string { return &c.APIKey })},
+	{"database_url", "DATABASE_URL", "", "PostgreSQL connection URL (required)", str(func(c *Config) *string { return &c.DatabaseURL })},
+	{"port", "PORT", "8085", "HTTP listen port", integer(func(c *Config) *int { return &c.Port })},
+	{"admin.token", "ADMIN_TOKEN", "", "Bearer token for /admin endpoints (if empty, they only answer localhost)", str(func(c *Config) *string { return &c.AdminToken })},
+	{"source", "SOURCE", "alphavantage", "Data source (" + strings.Join(Sources, ", ") + ")", oneOf(func(c *Config) *string { return &c.Source }, Sources...)},
+
+	{"db.max_conns", "DB_MAX_CONNS", "0", "Maximum database connections (0 for the pgx default)", integer(func(c *Config) *int { return &c.DBMaxConns })},
+	{"db.min_conns", "DB_MIN_CONNS", "0", "Minimum idle database connections", integer(func(c *Config) *int { return &c.DBMinConns })},
+
+	{"upstream.timeout", "UPSTREAM_TIMEOUT", "10s", "Timeout for a single upstream request", duration(func(c *Config) *time.Duration { return &c.UpstreamTimeout })},
+	{"upstream.max_retries", "UPSTREAM_MAX_RETRIES", "3", "Retries on network errors, 429 and 5xx", integer(func(c *Config) *int { return &c.UpstreamMaxRetries })},
+	{"upstream.base_backoff", "UPSTREAM_BASE_BACKOFF", "500ms", "Initial retry backoff", duration(func(c *Config) *time.Duration { return &c.UpstreamBaseBackoff })},
+	{"upstream.max_backoff", "UPSTREAM_MAX_BACKOFF", "5s", "Upper bound for a single backoff", duration(func(c *Config) *time.Duration { return &c.UpstreamMaxBackoff })},
+	{"upstream.breaker_threshold", "UPSTREAM_BREAKER_THRESHOLD", "5", "Failed fetches before the circuit breaker opens", integer(func(c *Config) *int { return &c.UpstreamBreakerThreshold })},
+	{"upstream.breaker_cooldown", "UPSTREAM_BREAKER_COOLDOWN", "1m", "How long the circuit breaker stays open", duration(func(c *Config) *time.Duration { return &c.UpstreamBreakerCooldown })},
+	{"upstream.rate_limit", "UPSTREAM_RATE_LIMIT", "5", "Upstream requests a minute, including retries (0 for no limit)", integer(func(c *Config) *int { return &c.UpstreamRateLimit })},
+
+	{"metrics.interval", "METRICS_INTERVAL", "10s", "Process metrics sampling interval", duration(func(c *Config) *time.Duration { return &c.MetricsInterval })},
+	{"availability_file", "AVAILABILITY_FILE", "availability.json", "File uptime heartbeats are persisted to", str(func(c *Config) *string { return &c.AvailabilityFile })},
+
+	{"jobs.workers", "JOB_WORKERS", "2", "Jobs processed concurrently", integer(func(c *Config) *int { return &c.JobWorkers })},
+	{"jobs.queue_size", "JOB_QUEUE_SIZE", "100", "Pending jobs before submissions are rejected", integer(func(c *Config) *int { return &c.JobQueueSize })},
+	{"jobs.refresh_interval", "REFRESH_INTERVAL", "24h", "How often a refresh job is scheduled (0 disables)", duration(func(c *Config) *time.Duration { return &c.RefreshInterval })},
+	{"jobs.export_dir", "EXPORT_DIR", "exports", "Where snapshot archives are written", str(func(c *Config) *string { return &c.ExportDir })},
+	{"jobs.export_format", "EXPORT_FORMAT", "csv", "Table format in snapshot archives (csv or json)", oneOf(func(c *Config) *string { return &c.ExportFormat }, "csv", "json")},
+	{"jobs.export_interval", "EXPORT_INTERVAL", "0", "How often a snapshot is exported (0 disables)", duration(func(c *Config) *time.Duration { return &c.ExportInterval })},
+	{"jobs.export_keep", "EXPORT_KEEP", "0", "Newest snapshots to keep (0 keeps all)", integer(func(c *Config) *int { return &c.ExportKeep })},
+
+	{"tracing.exporter", "TRACING_EXPORTER", "", "Tracing exporter (otlp, stdout, local or none)", oneOf(func(c *Config) *string { return &c.TracingExporter }, "", "otlp", "stdout", "local", "none")},
+	{"tracing.endpoint", "TRACING_ENDPOINT", "", "OTLP/HTTP collector URL", str(func(c *Config) *string { return &c.TracingEndpoint })},
+
+	{"slo.objectives", "SLOS", "", "Service level objectives, <route>=<availability%>[,<latency>@<latency%>] separated by ;", sloList},
+	{"slo.interval", "SLO_INTERVAL", "1m", "How often SLOs are evaluated", duration(func(c *Config) *time.Duration { return &c.SLOInterval })},
+
+	{"log.format", "LOG_FORMAT", "text", "Log format (text or json)", oneOf(func(c *Config) *string { return &c.LogFormat }, "text", "json")},
+	{"log.level", "LOG_LEVEL", "info", "Log level (debug, info, warn or error)", oneOf(func(c *Config) *string { return &c.LogLevel }, "debug", "info", "warn", "error")},
+
+	{"http.read_timeout", "HTTP_READ_TIMEOUT", "15s", "Time to read a whole request", duration(func(c *Config) *time.Duration { return &c.ReadTimeout })},
+	{"http.read_header_timeout", "HTTP_READ_HEADER_TIMEOUT", "5s", "Time to read request headers", duration(func(c *Config) *time.Duration { return &c.ReadHeaderTimeout })},
+	{"http.write_timeout", "HTTP_WRITE_TIMEOUT", "1m", "Time to write a response", duration(func(c *Config) *time.Duration { return &c.WriteTimeout })},
+	{"http.idle_timeout", "HTTP_IDLE_TIMEOUT", "2m", "Keep-alive connection idle time", duration(func(c *Config) *time.Duration { return &c.IdleTimeout })},
+	{"http.shutdown_timeout", "SHUTDOWN_TIMEOUT", "30s", "How long shutdown waits for in-flight requests", duration(func(c *Config) *time.Duration { return &c.ShutdownTimeout })},
+
+	{"health.check_timeout", "HEALTH_CHECK_TIMEOUT", "2s", "Timeout for each readiness check", duration(func(c *Config) *time.Duration { return &c.HealthCheckTimeout })},
+	{"health.max_refresh_age", "HEALTH_MAX_REFRESH_AGE", "72h", "Oldest acceptable data refresh (0 disables the check)", duration(func(c *Config) *time.Duration { return &c.HealthMaxRefreshAge })},
+	{"health.max_job_runtime", "HEALTH_MAX_JOB_RUNTIME", "15m", "Longest a job may run before liveness fails (0 disables the check)", duration(func(c *Config) *time.Duration { return &c.HealthMaxJobRuntime })},
+}
+
+// ValidationError lists every problem found while loading the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
 }
 
-// LoadConfig loads the configuration from the .env file
+// LoadConfig loads the configuration from defaults, an optional config file, the .env
+// file and the environment, without command-line flags.
 func LoadConfig() (*Config, error) {
-	// Load .env file
-	err := godotenv.Load()
+	return Load(nil)
+}
+
+// Load builds the configuration by layering, from lowest to highest precedence:
+// defaults, the config file (--config or CONFIG_FILE, YAML or TOML), the .env file if
+// present, environment variables and the command-line flags in args.
+// Every invalid or missing value is reported at once in a *ValidationError.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	config, err := LoadFlagSet(flags, args)
 	if err != nil {
-		log.Printf("Error loading .env file: %v", err)
 		return nil, err
 	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	return config, nil
+}
 
-	// Read values from environment variables
-	config := &Config{
-		APIKey:      os.Getenv("API_KEY"),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
+// LoadFlagSet is Load for commands with flags of their own: it adds the configuration
+// flags to flags, parses args with it and leaves any positional arguments in flags.Args().
+func LoadFlagSet(flags *flag.FlagSet, args []string) (*Config, error) {
+	values := make(map[string]string)
+	origins := make(map[string]string)
+	secretFiles := make(map[string]string)
+	for _, s := range settings {
+		values[s.key], origins[s.key] = s.def, OriginDefault
 	}
-	// Ensure required variables are set
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("API_KEY is not set in the environment")
+
+	// Flags are parsed first so --config can select the file, but applied last.
+	configFile, flagValues := defineFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
 	}
-	if config.DatabaseURL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is not set in the environment")
+
+	var problems []string
+	dotEnv, err := godotenv.Read(dotEnvFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		problems = append(problems, fmt.Sprintf("%s: %v", dotEnvFile, err))
 	}
+	exportDotEnv(dotEnv)
 
-	log.Println("Configuration loaded from .env file successfully!")
+	file := *configFile
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file == "" {
+		file = dotEnv["CONFIG_FILE"]
+	}
+	if file != "" {
+		fileValues, err := readFile(file)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		problems = append(problems, applyLayer(values, origins, secretFiles, fileValues, OriginFile)...)
+	}
+
+	dotEnvValues := envLayer(func(name string) string { return dotEnv[name] })
+	problems = append(problems, applyLayer(values, origins, secretFiles, dotEnvValues, OriginDotEnv)...)
+	problems = append(problems, applyLayer(values, origins, secretFiles, envLayer(os.Getenv), OriginEnv)...)
+	problems = append(problems, applyLayer(values, origins, secretFiles, flagValues, OriginFlag)...)
+
+	config := &Config{File: file, values: values, origins: origins, secretFiles: secretFiles}
+	if dotEnv != nil {
+		config.dotEnv = dotEnvFile
+	}
+	for _, s := range settings {
+		if err := s.apply(config, values[s.key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v (%s)", s.key, err, describeOrigin(s, origins[s.key])))
+		}
+	}
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	redact.Register(config.APIKey, config.AdminToken, redact.URLPassword(config.DatabaseURL))
+
+	slog.Info("Configuration loaded", "file", file)
 	return config, nil
 }
+
+// envLayer returns the settings found by looking up their environment variable names,
+// including the <NAME>_FILE variants of secret settings.
+func envLayer(lookup func(string) string) map[string]string {
+	layer := make(map[string]string)
+	for _, s := range settings {
+		if value := lookup(s.env); value != "" {
+			layer[s.key] = value
+		}
+		if value := lookup(s.env + "_FILE"); value != "" && secretSettings[s.key] {
+			layer[s.key+"_file"] = value
+		}
+	}
+	return layer
+}
+
+// exportDotEnv sets the variables in the .env file that are not settings and not already
+// set, so code reading the environment itself (such as the OpenTelemetry SDK) sees them.
+// Settings are left to their own layer, so that a reload sees changes to the file.
+func exportDotEnv(dotEnv map[string]string) {
+	for name, value := range dotEnv {
+		if name == "CONFIG_FILE" || isSettingEnv(name) {
+			continue
+		}
+		if _, set := os.LookupEnv(name); !set {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// isSettingEnv reports whether name is the environment variable of a setting, or of its file.
+func isSettingEnv(name string) bool {
+	for _, s := range settings {
+		if name == s.env || (secretSettings[s.key] && name == s.env+"_FILE") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyLayer overrides values with the settings of one layer. For secret settings a
+// "<key>_file" entry names a file holding the value; its trailing newline is dropped
+// and the file is recorded in secretFiles.
+func applyLayer(values, origins, secretFiles, layer map[string]string, origin string) []string {
+	keys := make([]string, 0, len(layer))
+	for key := range layer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		value := layer[key]
+		base, isFile := strings.CutSuffix(key, "_file")
+		if !isFile || !secretSettings[base] {
+			values[key], origins[key] = value, origin
+			delete(secretFiles, key)
+			continue
+		}
+		if _, both := layer[base]; both {
+			problems = append(problems, fmt.Sprintf("%s: set either the value or a file, not both (%s)", base, origin))
+			continue
+		}
+		content, err := os.ReadFile(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: reading secret file: %v (%s)", base, err, origin))
+			continue
+		}
+		values[base], origins[base] = strings.TrimRight(string(content), "\r\n"), origin
+		secretFiles[base] = value
+	}
+	return problems
+}
+
+// defineFlags defines a flag per setting plus --config on flags. Only flags that were
+// actually passed end up in the returned map, so unset flags do not override other layers.
+func defineFlags(flags *flag.FlagSet) (*string, map[string]string) {
+	configFile := flags.String("config", "", "Configuration file (YAML or TOML); overrides CONFIG_FILE")
+	values := make(map[string]string)
+	for _, s := range settings {
+		key := s.key
+		usage := fmt.Sprintf("%s (env %s", s.usage, s.env)
+		if s.def != "" {
+			usage += ", default " + s.def
+		}
+		flags.Func(s.flagName(), usage+")", func(value string) error {
+			values[key] = value
+			return nil
+		})
+		if secretSettings[key] {
+			flags.Func(s.flagName()+"-file", "File containing the value of --"+s.flagName(), func(value string) error {
+				values[key+"_file"] = value
+				return nil
+			})
+		}
+	}
+	return configFile, values
+}
+
+// describeOrigin says where a bad value came from, to point at what needs fixing.
+func describeOrigin(s setting, origin string) string {
+	switch origin {
+	case OriginEnv:
+		return "from environment variable " + s.env
+	case OriginFlag:
+		return "from flag --" + s.flagName()
+	case OriginFile:
+		return "from the config file"
+	case OriginDotEnv:
+		return "from " + s.env + " in " + dotEnvFile
+	default:
+		return "default"
+	}
+}
+
+// Origin returns where the value of the setting with the given key came from.
+func (c *Config) Origin(key string) string {
+	return c.origins[key]
+}
+
+// Setting is the effective value of one setting, as listed by GET /admin/config.
+type Setting struct {
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+	Live   bool   `json:"live"` // Whether a reload applies changes without a restart.
+}
+
+// Settings returns every setting with its effective value and origin. Secrets are
+// replaced by a placeholder (only the password of the database URL is hidden) and
+// every other value is redacted as well.
+func (c *Config) Settings() []Setting {
+	list := make([]Setting, 0, len(settings))
+	for _, s := range settings {
+		value := c.values[s.key]
+		switch {
+		case value == "":
+		case s.key == "database_url":
+			value = redact.String(value)
+		case secretSettings[s.key]:
+			value = redact.Placeholder
+		default:
+			value = redact.String(value)
+		}
+		list = append(list, Setting{Key: s.key, Env: s.env, Value: value, Origin: c.origins[s.key], Live: liveSettings[s.key]})
+	}
+	return list
+}
+
+// validate checks constraints between values and returns every problem found.
+func (c *Config) validate() []string {
+	var problems []string
+	if c.APIKey == "" {
+		problems = append(problems, "api_key: required (set API_KEY)")
+	}
+	if c.DatabaseURL == "" {
+		problems = append(problems, "database_url: required (set DATABASE_URL)")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("port: must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.DBMaxConns > 0 && c.DBMinConns > c.DBMaxConns {
+		problems = append(problems, fmt.Sprintf("db.min_conns: %d exceeds db.max_conns %d", c.DBMinConns, c.DBMaxConns))
+	}
+	if c.UpstreamMaxBackoff < c.UpstreamBaseBackoff {
+		problems = append(problems, fmt.Sprintf("upstream.max_backoff: %s is less than upstream.base_backoff %s", c.UpstreamMaxBackoff, c.UpstreamBaseBackoff))
+	}
+	if c.MetricsInterval == 0 {
+		problems = append(problems, "metrics.interval: must be greater than zero")
+	}
+	if c.JobWorkers == 0 {
+		problems = append(problems, "jobs.workers: must be at least 1")
+	}
+	if c.JobQueueSize == 0 {
+		problems = append(problems, "jobs.queue_size: must be at least 1")
+	}
+	return problems
+}
+
+// str sets a string field as-is.
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// oneOf sets a string field, accepting only the allowed values (case-insensitively).
+func oneOf(field func(*Config) *string, allowed ...string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		value = strings.ToLower(value)
+		for _, a := range allowed {
+			if value == a {
+				*field(c) = value
+				return nil
+			}
+		}
+		var names []string
+		for _, a := range allowed {
+			if a != "" {
+				names = append(names, a)
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(names, ", "), value)
+	}
+}
+
+// duration sets a duration field from a value such as "750ms" or "2m".
+func duration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("must be a non-negative duration, got %q", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// integer sets an int field from a non-negative integer.
+func integer(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("must be a non-negative integer, got %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// sloList sets the SLOs from their declarations.
+func sloList(c *Config, value string) error {
+	slos, err := parseSLOs(value)
+	if err != nil {
+		return err
+	}
+	c.SLOs = slos
+	return nil
+}
+
+// parseSLOs parses SLO declarations of the form
+// "<route>=<availability%>[,<latency threshold>@<latency%>]" separated by semicolons,
+// e.g. "/=99.5,300ms@99;/auth/{email}=99.9". Either objective may be left empty.
+func parseSLOs(spec string) ([]SLO, error) {
+	var slos []SLO
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, objectives, ok := strings.Cut(entry, "=")
+		if !ok || route == "" {
+			return nil, fmt.Errorf("SLOS entry %q must look like <route>=<availability%%>[,<threshold>@<latency%%>]", entry)
+		}
+		slo := SLO{Route: strings.TrimSpace(route)}
+		availability, latency, _ := strings.Cut(objectives, ",")
+		if availability = strings.TrimSpace(availability); availability != "" {
+			target, err := parsePercent(availability)
+			if err != nil {
+				return nil, fmt.Errorf("SLOS entry %q: availability %v", entry, err)
+			}
+			slo.AvailabilityTarget = target
+		}
+		if latency = strings.TrimSpace(latency); latency != "" {
+			threshold, target, ok := strings.Cut(latency, "@")
+			if !ok {
+				return nil, fmt.Errorf("SLOS entry %q: latency must look like <threshold>@<percent>", entry)
+			}
+			d, err := time.ParseDuration(threshold)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("SLOS entry %q: invalid latency threshold %q", entry, threshold)
+			}
+			pct, err := parsePercent(target)
+			if err != nil {
+				return nil, fmt.Errorf("SLOS entry %q: latency %v", entry, err)
+			}
+			slo.LatencyThreshold, slo.LatencyTarget = d, pct
+		}
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// parsePercent parses a target percentage strictly between 0 and 100.
+func parsePercent(value string) (float64, error) {
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil || pct <= 0 || pct >= 100 {
+		return 0, fmt.Errorf("target must be a percentage between 0 and 100, got %q", value)
+	}
+	return pct, nil
+}
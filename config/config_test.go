@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolate runs the test in an empty working directory, so no .env file is found, with
+// the environment variables of every setting cleared.
+func isolate(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+		if secretSettings[s.key] {
+			t.Setenv(s.env+"_FILE", "")
+		}
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	required := map[string]string{"API_KEY": "env-key", "DATABASE_URL": "postgres://localhost/test"}
+
+	tests := []struct {
+		name   string
+		files  map[string]string // Files to create in the working directory, by name.
+		env    map[string]string // Set on top of required.
+		args   []string
+		key    string
+		want   string
+		origin string
+	}{
+		{
+			name: "default",
+			key:  "port", want: "8085", origin: OriginDefault,
+		},
+		{
+			name:  "config file overrides default",
+			files: map[string]string{"config.yaml": "port: 9000\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml"},
+			key:   "port", want: "9000", origin: OriginFile,
+		},
+		{
+			name:  "nested keys in a config file",
+			files: map[string]string{"config.yaml": "upstream:\n  timeout: 3s\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml"},
+			key:   "upstream.timeout", want: "3s", origin: OriginFile,
+		},
+		{
+			name:  "TOML config file selected by flag",
+			files: map[string]string{"config.toml": "[log]\nlevel = \"debug\"\n"},
+			args:  []string{"--config", "config.toml"},
+			key:   "log.level", want: "debug", origin: OriginFile,
+		},
+		{
+			name:  ".env overrides config file",
+			files: map[string]string{"config.yaml": "port: 9000\n", ".env": "PORT=9001\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml"},
+			key:   "port", want: "9001", origin: OriginDotEnv,
+		},
+		{
+			name:  ".env can select the config file",
+			files: map[string]string{"config.yaml": "port: 9000\n", ".env": "CONFIG_FILE=config.yaml\n"},
+			key:   "port", want: "9000", origin: OriginFile,
+		},
+		{
+			name:  "environment overrides .env",
+			files: map[string]string{".env": "PORT=9001\n"},
+			env:   map[string]string{"PORT": "9002"},
+			key:   "port", want: "9002", origin: OriginEnv,
+		},
+		{
+			name:  "flag overrides environment",
+			files: map[string]string{"config.yaml": "port: 9000\n", ".env": "PORT=9001\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml", "PORT": "9002"},
+			args:  []string{"--port", "9003"},
+			key:   "port", want: "9003", origin: OriginFlag,
+		},
+		{
+			name:  "secret read from a file without its trailing newline",
+			files: map[string]string{"api_key": "file-key\n"},
+			env:   map[string]string{"API_KEY": "", "API_KEY_FILE": "api_key"},
+			key:   "api_key", want: "file-key", origin: OriginEnv,
+		},
+		{
+			name:  "secret file in a higher layer replaces the value",
+			files: map[string]string{"api_key": "file-key\n"},
+			args:  []string{"--api-key-file", "api_key"},
+			key:   "api_key", want: "file-key", origin: OriginFlag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			for name, value := range required {
+				t.Setenv(name, value)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			c, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := c.values[tt.key]; got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+			}
+			if got := c.Origin(tt.key); got != tt.origin {
+				t.Errorf("Origin(%q) = %q, want %q", tt.key, got, tt.origin)
+			}
+		})
+	}
+}
+
+func TestLoadAppliesValues(t *testing.T) {
+	isolate(t)
+	t.Setenv("API_KEY", "env-key")
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+	t.Setenv("UPSTREAM_TIMEOUT", "3s")
+	t.Setenv("LOG_LEVEL", "WARN")
+
+	c, err := Load([]string{"--port", "9000"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.APIKey != "env-key" || c.Port != 9000 || c.UpstreamTimeout.String() != "3s" || c.LogLevel != "warn" {
+		t.Errorf("Load() = api_key %q, port %d, upstream.timeout %s, log.level %q",
+			c.APIKey, c.Port, c.UpstreamTimeout, c.LogLevel)
+	}
+}
+
+func TestLoadValidationError(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		args  []string
+		want  []string // One substring per expected problem.
+	}{
+		{
+			name: "required settings missing",
+			want: []string{"api_key: required", "database_url: required"},
+		},
+		{
+			name: "every invalid value is reported",
+			env: map[string]string{
+				"API_KEY": "env-key", "DATABASE_URL": "postgres://localhost/test",
+				"PORT": "eighty", "LOG_LEVEL": "loud", "JOB_WORKERS": "0",
+			},
+			args: []string{"--upstream-timeout", "soon"},
+			want: []string{
+				"port: ", "from environment variable PORT",
+				"upstream.timeout: ", "from flag --upstream-timeout",
+				"log.level: ", "from environment variable LOG_LEVEL",
+				"jobs.workers: must be at least 1",
+			},
+		},
+		{
+			name:  "config file and .env problems are reported with the others",
+			files: map[string]string{"config.yaml": "port: 9000\nunknown: 1\n", ".env": "DB_MAX_CONNS=lots\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml", "DATABASE_URL": "postgres://localhost/test"},
+			want: []string{
+				"unknown settings unknown",
+				"db.max_conns: ", "from DB_MAX_CONNS in .env",
+				"api_key: required",
+			},
+		},
+		{
+			name:  "constraints between settings",
+			files: map[string]string{"config.yaml": "upstream:\n  base_backoff: 10s\n  max_backoff: 1s\ndb:\n  max_conns: 2\n  min_conns: 4\n"},
+			env:   map[string]string{"CONFIG_FILE": "config.yaml", "API_KEY": "env-key", "DATABASE_URL": "postgres://localhost/test"},
+			want:  []string{"db.min_conns: 4 exceeds db.max_conns 2", "upstream.max_backoff: 1s is less than upstream.base_backoff 10s"},
+		},
+		{
+			name:  "secret value and file in the same layer",
+			files: map[string]string{"api_key": "file-key\n"},
+			env:   map[string]string{"API_KEY": "env-key", "API_KEY_FILE": "api_key", "DATABASE_URL": "postgres://localhost/test"},
+			want:  []string{"api_key: set either the value or a file, not both (env)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := isolate(t)
+			for name, content := range tt.files {
+				writeFile(t, filepath.Join(dir, name), content)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(tt.args)
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Fatalf("Load() error = %v, want a *ValidationError", err)
+			}
+			problems := strings.Join(validation.Problems, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(problems, want) {
+					t.Errorf("problems do not mention %q:\n%s", want, problems)
+				}
+			}
+			if !strings.HasPrefix(err.Error(), "invalid configuration:\n  - ") {
+				t.Errorf("Error() = %q, want the problems listed", err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadRejectsArguments(t *testing.T) {
+	isolate(t)
+	t.Setenv("API_KEY", "env-key")
+	t.Setenv("DATABASE_URL", "postgres://localhost/test")
+
+	_, err := Load([]string{"extra"})
+	var validation *ValidationError
+	if err == nil || errors.As(err, &validation) {
+		t.Errorf("Load() error = %v, want an unexpected arguments error", err)
+	}
+}
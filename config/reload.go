@@ -0,0 +1,55 @@
+package config
+
+// liveSettings can be applied to a running server by a reload. Every other setting
+// only takes effect after a restart.
+var liveSettings = map[string]bool{
+	"api_key":                    true,
+	"admin.token":                true,
+	"upstream.timeout":           true,
+	"upstream.max_retries":       true,
+	"upstream.base_backoff":      true,
+	"upstream.max_backoff":       true,
+	"upstream.breaker_threshold": true,
+	"upstream.breaker_cooldown":  true,
+	"upstream.rate_limit":        true,
+	"jobs.refresh_interval":      true,
+	"jobs.export_interval":       true,
+	"log.level":                  true,
+}
+
+// Changes compares two configurations and returns the keys of the settings whose
+// values differ, split into those that can be applied live and those that need a restart.
+func Changes(old, new *Config) (live, restart []string) {
+	for _, s := range settings {
+		if old.values[s.key] == new.values[s.key] {
+			continue
+		}
+		if liveSettings[s.key] {
+			live = append(live, s.key)
+		} else {
+			restart = append(restart, s.key)
+		}
+	}
+	if old.File != new.File {
+		restart = append(restart, "config")
+	}
+	return live, restart
+}
+
+// WatchedFiles returns the files the configuration was read from: the config file and
+// the .env file, if any, and the files secret settings were read from.
+func (c *Config) WatchedFiles() []string {
+	var files []string
+	if c.File != "" {
+		files = append(files, c.File)
+	}
+	if c.dotEnv != "" {
+		files = append(files, c.dotEnv)
+	}
+	for _, s := range settings {
+		if file := c.secretFiles[s.key]; file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce coalesces the bursts of events editors and secret mounts produce for one change.
+const watchDebounce = 250 * time.Millisecond
+
+// Watch calls onChange whenever one of the files changes, until ctx is cancelled.
+// The parent directories are watched rather than the files themselves, so files
+// replaced by a rename (as editors do) or behind a swapped symlink (as Kubernetes
+// does for ConfigMap and Secret volumes) keep being noticed.
+func Watch(ctx context.Context, files []string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watching config files: %v", err)
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]bool) // Cleaned file paths and Kubernetes "..data" links.
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		file = filepath.Clean(file)
+		dir := filepath.Dir(file)
+		watched[file] = true
+		watched[filepath.Join(dir, "..data")] = true
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("watching %s: %v", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if watched[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				debounce = time.After(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("Config file watcher error", "error", err)
+		case <-debounce:
+			debounce = nil
+			onChange()
+		}
+	}
+}
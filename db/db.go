@@ -2,33 +2,59 @@ package db
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 
-	"federal-funds-rate-metrics-ByYear/metrics" // import your metrics package
+	"federal-funds-rate-metrics-ByYear/appmetrics" // import your metrics package
+	"federal-funds-rate-metrics-ByYear/tracing"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-var Conn *pgx.Conn
+// Conn is a connection pool, safe for use by concurrent handlers and background jobs.
+var Conn *pgxpool.Pool
 
-// Connect initializes the database connection
-func Connect(databaseURL string) {
-	var err error
-	Conn, err = pgx.Connect(context.Background(), databaseURL)
+// Connect initializes the database connection pool and checks that it can reach the database.
+// Non-zero maxConns and minConns override the pool size limits from the URL or pgx defaults.
+func Connect(databaseURL string, maxConns, minConns int) error {
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err == nil {
+		if maxConns > 0 {
+			poolConfig.MaxConns = int32(maxConns)
+		}
+		if minConns > 0 {
+			poolConfig.MinConns = int32(minConns)
+		}
+		// Create a tracing span for every query
+		poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+		Conn, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
+	}
+	if err == nil {
+		err = Conn.Ping(context.Background())
+	}
 	if err != nil {
-		log.Fatalf("Unable to connect to the database: %v\n", err)
+		return fmt.Errorf("unable to connect to the database: %v", err)
 	}
-	log.Println("Connected to PostgreSQL database!")
-	// Update the DB connection metric (for a single connection)
-	metrics.UpdateDBConnections(1)
+	slog.Info("Connected to PostgreSQL database!", "max_conns", Conn.Config().MaxConns)
+	// Update the DB connection metric from the pool size
+	appmetrics.UpdateDBConnections(int(Conn.Stat().TotalConns()))
+	return nil
 }
 
-// Close terminates the database connection
+// Close terminates the database connection pool
 func Close() {
 	if Conn != nil {
-		Conn.Close(context.Background())
-		log.Println("Disconnected from PostgreSQL database.")
+		Conn.Close()
+		slog.Info("Disconnected from PostgreSQL database.")
 		// Update the DB connection metric to 0 once closed
-		metrics.UpdateDBConnections(0)
+		appmetrics.UpdateDBConnections(0)
+	}
+}
+
+// Ping checks that the database connection is alive.
+func Ping(ctx context.Context) error {
+	if Conn == nil {
+		return fmt.Errorf("database not connected")
 	}
+	return Conn.Ping(ctx)
 }
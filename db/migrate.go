@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles holds the SQL migrations, named <version>_<description>.sql.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a single versioned schema change.
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrate applies all pending migrations in order, each in its own transaction,
+// and returns how many were applied.
+func Migrate(ctx context.Context) (int, error) {
+	_, err := Conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create schema_migrations: %v", err)
+	}
+
+	current, err := MigrationVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		tx, err := Conn.Begin(ctx)
+		if err != nil {
+			return applied, fmt.Errorf("failed to begin migration %s: %v", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("failed to apply migration %s: %v", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", m.Version); err != nil {
+			tx.Rollback(ctx)
+			return applied, fmt.Errorf("failed to record migration %s: %v", m.Name, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return applied, fmt.Errorf("failed to commit migration %s: %v", m.Name, err)
+		}
+		slog.InfoContext(ctx, "Applied migration", "name", m.Name, "version", m.Version)
+		applied++
+	}
+	return applied, nil
+}
+
+// MigrationVersion returns the highest applied migration version, or 0 if none.
+func MigrationVersion(ctx context.Context) (int, error) {
+	var version int
+	err := Conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %v", err)
+	}
+	return version, nil
+}
+
+// LatestMigration returns the version of the newest embedded migration.
+func LatestMigration() int {
+	migrations, err := loadMigrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// loadMigrations reads the embedded migrations sorted by version.
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is not named <version>_<description>.sql", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %v", name, err)
+		}
+		sql, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{Version: version, Name: name, SQL: string(sql)})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -0,0 +1,17 @@
+-- Tables used before migrations were introduced. IF NOT EXISTS keeps this
+-- safe to apply against databases that were created by hand.
+CREATE TABLE IF NOT EXISTS users (
+    id    SERIAL PRIMARY KEY,
+    name  TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS federal_funds_insights (
+    year               INTEGER PRIMARY KEY,
+    average_rate       DOUBLE PRECISION NOT NULL,
+    highest_rate       DOUBLE PRECISION NOT NULL,
+    lowest_rate        DOUBLE PRECISION NOT NULL,
+    growth_percentage  DOUBLE PRECISION NOT NULL,
+    highest_rate_month TEXT NOT NULL,
+    lowest_rate_month  TEXT NOT NULL
+);
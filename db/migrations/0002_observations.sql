@@ -0,0 +1,7 @@
+-- Raw monthly observations, so insights can be recomputed without refetching.
+CREATE TABLE IF NOT EXISTS federal_funds_observations (
+    date       DATE PRIMARY KEY,
+    value      DOUBLE PRECISION NOT NULL,
+    source     TEXT NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+-- fetched_at is only set by refreshes, so imports do not count towards the refresh age;
+-- imported rows record imported_at instead.
+ALTER TABLE federal_funds_observations ALTER COLUMN fetched_at DROP NOT NULL;
+ALTER TABLE federal_funds_observations ALTER COLUMN fetched_at DROP DEFAULT;
+ALTER TABLE federal_funds_observations ADD COLUMN IF NOT EXISTS imported_at TIMESTAMPTZ;
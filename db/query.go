@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Query outcomes recorded in the db_query_duration_seconds histogram.
+const (
+	OutcomeSuccess = "success"
+	OutcomeNoRows  = "no_rows"
+	OutcomeError   = "error"
+)
+
+// Query runs a query that returns rows. The duration and outcome are recorded under
+// operation when the returned rows are closed, so errors during iteration are counted.
+func Query(ctx context.Context, operation, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := Conn.Query(ctx, sql, args...)
+	if err != nil {
+		record(ctx, operation, start, err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, ctx: ctx, operation: operation, start: start}, nil
+}
+
+// QueryRow runs a query expected to return at most one row. The duration and outcome
+// are recorded under operation when the row is scanned.
+func QueryRow(ctx context.Context, operation, sql string, args ...any) pgx.Row {
+	start := time.Now()
+	return &instrumentedRow{Row: Conn.QueryRow(ctx, sql, args...), ctx: ctx, operation: operation, start: start}
+}
+
+// Exec runs a statement that returns no rows, recording its duration and outcome under operation.
+func Exec(ctx context.Context, operation, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := Conn.Exec(ctx, sql, args...)
+	record(ctx, operation, start, err)
+	return tag, err
+}
+
+// SendBatch runs the queued statements of batch in one round trip. They run in an implicit
+// transaction, so either all of them take effect or none do. The duration and outcome are
+// recorded under operation.
+func SendBatch(ctx context.Context, operation string, batch *pgx.Batch) error {
+	start := time.Now()
+	err := Conn.SendBatch(ctx, batch).Close()
+	record(ctx, operation, start, err)
+	return err
+}
+
+// instrumentedRows records metrics once iteration is finished.
+type instrumentedRows struct {
+	pgx.Rows
+	ctx       context.Context
+	operation string
+	start     time.Time
+	recorded  bool
+}
+
+// Next advances to the next row, recording metrics when the result set is exhausted.
+func (r *instrumentedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.finish()
+	return false
+}
+
+// Close closes the rows and records metrics if they have not been recorded yet.
+func (r *instrumentedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *instrumentedRows) finish() {
+	if r.recorded {
+		return
+	}
+	r.recorded = true
+	record(r.ctx, r.operation, r.start, r.Rows.Err())
+}
+
+// instrumentedRow records metrics when scanned.
+type instrumentedRow struct {
+	pgx.Row
+	ctx       context.Context
+	operation string
+	start     time.Time
+}
+
+// Scan reads the row into dest and records the query outcome.
+func (r *instrumentedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	record(r.ctx, r.operation, r.start, err)
+	return err
+}
+
+// record observes the query duration and, on failure, counts the error by SQLSTATE class.
+func record(ctx context.Context, operation string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	switch {
+	case err == nil:
+	case errors.Is(err, pgx.ErrNoRows):
+		outcome = OutcomeNoRows
+	default:
+		outcome = OutcomeError
+		appmetrics.RecordDBError(operation, sqlStateClass(err))
+	}
+	appmetrics.RecordDBQuery(ctx, operation, outcome, time.Since(start))
+}
+
+// sqlStateClass returns the two-character SQLSTATE class of a PostgreSQL error
+// (e.g. "23" for integrity violations), or "client" for errors raised before
+// reaching the server such as connection failures and cancellations.
+func sqlStateClass(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && len(pgErr.Code) >= 2 {
+		return pgErr.Code[:2]
+	}
+	return "client"
+}
@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"time"
+
 	"federal-funds-rate-metrics-ByYear/models"
 )
 
@@ -17,10 +19,27 @@ type Message struct {
 type MessageInsights struct {
 	Status  string         `json:"status"`          // Status of the response (e.g., success, error).
 	Message string         `json:"message,omitempty"` // Optional message providing additional details.
+	Stale   bool           `json:"stale"`             // True when the upstream was unavailable and stored data is served instead.
 	Data    []YearlyInsight `json:"data,omitempty"`    // Array of yearly insights data.
 }
 
 
+// ErrorResponse is the body of every error response.
+// Code is a stable machine-readable identifier; Message is meant for humans.
+type ErrorResponse struct {
+	Status    string       `json:"status"`               // Always "error".
+	Code      string       `json:"code"`                 // Error code (e.g., not_found, conflict, validation_failed).
+	Message   string       `json:"message"`              // Human-readable description of the error.
+	Details   []FieldError `json:"details,omitempty"`    // Per-field problems for validation errors.
+	RequestID string       `json:"request_id,omitempty"` // ID of the request, as found in the logs.
+}
+
+// FieldError describes a problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`   // Name of the offending field.
+	Problem string `json:"problem"` // What is wrong with it (e.g., required, email).
+}
+
 // YearlyInsight represents a structure to hold insights about a specific year.
 // Includes financial rates, growth percentage, and associated months.
 type YearlyInsight struct {
@@ -33,17 +52,94 @@ type YearlyInsight struct {
 	LowestRateMonth   string  // Month with the lowest rate.
 }
 
+// Observation represents a single monthly federal funds rate observation.
+type Observation struct {
+	Date   time.Time `json:"date"`   // First day of the month the rate applies to.
+	Value  float64   `json:"value"`  // Effective federal funds rate in percent.
+	Source string    `json:"source"` // Where the observation came from (e.g., alphavantage).
+}
+
+// PeriodInsight holds insights about one period (e.g., a quarter or a fiscal year).
+// Months are given as YYYY-MM since a period can span calendar years.
+type PeriodInsight struct {
+	Period           string  `json:"period"`             // Period label (e.g., 2024-Q3, FY2025-Q1, 1990s).
+	Start            string  `json:"start"`              // First day of the period.
+	End              string  `json:"end"`                // Last day of the period.
+	Observations     int     `json:"observations"`       // Monthly observations in the period; fewer than expected for partial periods.
+	AverageRate      float64 `json:"average_rate"`       // Average rate over the period.
+	HighestRate      float64 `json:"highest_rate"`       // Highest rate in the period.
+	LowestRate       float64 `json:"lowest_rate"`        // Lowest rate in the period.
+	GrowthPercentage float64 `json:"growth_percentage"`  // Change of the average from the previous period, 0 if it has no data or averaged 0.
+	HighestRateMonth string  `json:"highest_rate_month"` // Month with the highest rate.
+	LowestRateMonth  string  `json:"lowest_rate_month"`  // Month with the lowest rate.
+}
+
+// RollingPoint is the value of a rolling statistic at one observation.
+type RollingPoint struct {
+	Date  string  `json:"date"`  // Date of the observation the window ends at.
+	Rate  float64 `json:"rate"`  // Observed rate at that date.
+	Value float64 `json:"value"` // Value of the statistic.
+}
+
+// MessageRolling is the response of GET /analytics/rolling.
+type MessageRolling struct {
+	Status string         `json:"status"`          // Status of the response (e.g., success, error).
+	Stat   string         `json:"stat"`            // Statistic (mean, stddev, min, max or ewma).
+	Window int            `json:"window"`          // Observations per window, or the span for ewma.
+	Alpha  float64        `json:"alpha,omitempty"` // Smoothing factor, for ewma only.
+	Data   []RollingPoint `json:"data"`            // One point per observation, oldest first.
+}
+
+// ForecastPoint is the forecast rate for one future month with its prediction intervals.
+type ForecastPoint struct {
+	Date    string  `json:"date"`     // Month the forecast is for, on the day of the month of the last observation.
+	Value   float64 `json:"value"`    // Point forecast.
+	Lower80 float64 `json:"lower_80"` // Lower bound of the 80% prediction interval.
+	Upper80 float64 `json:"upper_80"` // Upper bound of the 80% prediction interval.
+	Lower95 float64 `json:"lower_95"` // Lower bound of the 95% prediction interval.
+	Upper95 float64 `json:"upper_95"` // Upper bound of the 95% prediction interval.
+}
+
+// ForecastAccuracy reports the errors of forecasting the last months from the observations before them.
+type ForecastAccuracy struct {
+	Origin string  `json:"origin"`         // Date of the last observation the model was fitted to.
+	Months int     `json:"months"`         // Number of months forecast and compared.
+	MAE    float64 `json:"mae"`            // Mean absolute error, in percentage points.
+	RMSE   float64 `json:"rmse"`           // Root mean squared error, in percentage points.
+	MAPE   float64 `json:"mape,omitempty"` // Mean absolute percentage error, over months with a non-zero rate.
+}
+
+// MessageForecast is the response of GET /forecast.
+type MessageForecast struct {
+	Status     string             `json:"status"`               // Status of the response (e.g., success, error).
+	Model      string             `json:"model"`                // Model (naive, drift, linear or holt).
+	Horizon    int                `json:"horizon"`              // Number of months forecast.
+	Parameters map[string]float64 `json:"parameters,omitempty"` // Fitted model parameters.
+	Data       []ForecastPoint    `json:"data"`                 // One point per future month, oldest first.
+	Backtest   *ForecastAccuracy  `json:"backtest,omitempty"`   // Accuracy over the last horizon months, if there is enough history.
+}
+
+// MessagePeriodInsights is the response of GET /insights.
+type MessagePeriodInsights struct {
+	Status           string          `json:"status"`             // Status of the response (e.g., success, error).
+	Period           string          `json:"period"`             // Period kind (quarter, half, year or decade).
+	FiscalStartMonth int             `json:"fiscal_start_month"` // First month of the fiscal year, 1 for calendar years.
+	Data             []PeriodInsight `json:"data"`               // Insights per period, newest first.
+}
+
 // UserDto represents a simplified structure for user information to be shared in responses.
 type UserDto struct {
-	Name  string `json:"name"`  // User's name.
-	Email string `json:"email"` // User's email address.
+	Name  string `json:"name" validate:"required"`        // User's name.
+	Email string `json:"email" validate:"required,email"` // User's email address.
 }
 
 // AlphaVantageResponse represents the structure for responses from the Alpha Vantage API.
 // Includes metadata and an array of data entries.
 type AlphaVantageResponse struct {
-	MetaData map[string]string   `json:"Meta Data"` // Metadata about the API response.
-	Data     []map[string]string `json:"Data"`      // Array of data entries, where each entry is a map of strings.
+	MetaData    map[string]string   `json:"Meta Data"`             // Metadata about the API response.
+	Data        []map[string]string `json:"Data"`                  // Array of data entries, where each entry is a map of strings.
+	Note        string              `json:"Note,omitempty"`        // Set by Alpha Vantage when the request was rate limited.
+	Information string              `json:"Information,omitempty"` // Set by Alpha Vantage for rate limits and quota messages.
 }
 
 // ConvertToUserDto converts a user model object into a UserDto object.
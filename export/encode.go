@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// Table formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Formats lists the supported table formats.
+var Formats = []string{FormatCSV, FormatJSON}
+
+// observationColumns and insightColumns are the CSV headers of the two tables.
+var (
+	observationColumns = []string{"date", "value", "source"}
+	insightColumns     = []string{"year", "average_rate", "highest_rate", "lowest_rate", "growth_percentage", "highest_rate_month", "lowest_rate_month"}
+)
+
+// WriteObservations writes observations as CSV with a header row, or as a JSON array.
+func WriteObservations(w io.Writer, format string, observations []dto.Observation) error {
+	if format == FormatJSON {
+		return writeJSON(w, observations)
+	}
+	records := make([][]string, 0, len(observations)+1)
+	records = append(records, observationColumns)
+	for _, o := range observations {
+		records = append(records, []string{o.Date.Format("2006-01-02"), formatFloat(o.Value), o.Source})
+	}
+	return writeCSV(w, format, records)
+}
+
+// WriteInsights writes yearly insights as CSV with a header row, or as a JSON array.
+func WriteInsights(w io.Writer, format string, insights []dto.YearlyInsight) error {
+	if format == FormatJSON {
+		return writeJSON(w, insights)
+	}
+	records := make([][]string, 0, len(insights)+1)
+	records = append(records, insightColumns)
+	for _, i := range insights {
+		records = append(records, []string{
+			strconv.Itoa(i.Year),
+			formatFloat(i.AverageRate),
+			formatFloat(i.HighestRate),
+			formatFloat(i.LowestRate),
+			formatFloat(i.GrowthPercentage),
+			i.HighestRateMonth,
+			i.LowestRateMonth,
+		})
+	}
+	return writeCSV(w, format, records)
+}
+
+func writeCSV(w io.Writer, format string, records [][]string) error {
+	if format != FormatCSV {
+		return fmt.Errorf("unknown format %q, use csv or json", format)
+	}
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
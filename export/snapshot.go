@@ -0,0 +1,244 @@
+// Package export writes snapshots of the observations and insights tables to
+// compressed archives with a manifest, for loading into a data warehouse.
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/services"
+)
+
+// Snapshot archives are named snapshot-<UTC time>.tar.gz, so they sort by creation time.
+const (
+	archivePrefix   = "snapshot-"
+	archiveSuffix   = ".tar.gz"
+	checksumSuffix  = ".sha256"
+	timestampLayout = "20060102T150405Z"
+
+	// ManifestFile is the name of the manifest inside an archive.
+	ManifestFile = "manifest.json"
+)
+
+// Options configures where and how snapshots are written.
+type Options struct {
+	Dir    string // Directory the archives are written to; created if missing.
+	Format string // Table format, csv or json.
+	Keep   int    // Newest archives to keep; older ones are deleted. 0 keeps all.
+}
+
+// Manifest describes the contents of a snapshot archive.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Format    string    `json:"format"`
+	Tables    []Table   `json:"tables"`
+}
+
+// Table describes one table file in a snapshot archive. Checksums and sizes are of
+// the uncompressed file.
+type Table struct {
+	Name    string   `json:"name"`              // Table name: observations or insights.
+	File    string   `json:"file"`              // File name inside the archive.
+	Rows    int      `json:"rows"`              // Number of data rows, excluding the CSV header.
+	Bytes   int      `json:"bytes"`             // Size of the file.
+	SHA256  string   `json:"sha256"`            // Hex-encoded SHA-256 of the file.
+	Sources []string `json:"sources,omitempty"` // Distinct sources the rows came from.
+	From    string   `json:"from,omitempty"`    // First date (observations) or year (insights).
+	To      string   `json:"to,omitempty"`      // Last date (observations) or year (insights).
+}
+
+// Snapshot writes the observations and insights tables to a new archive in opts.Dir
+// and returns its path and manifest. The archive holds manifest.json followed by
+// observations.<format> and insights.<format>; a <archive>.sha256 file next to it holds
+// the checksum of the archive in sha256sum format. Archives beyond opts.Keep are then deleted.
+func Snapshot(ctx context.Context, opts Options) (string, Manifest, error) {
+	if opts.Format != FormatCSV && opts.Format != FormatJSON {
+		return "", Manifest{}, fmt.Errorf("unknown format %q, use csv or json", opts.Format)
+	}
+	observations, err := services.GetObservations(ctx)
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	insights, err := services.GetAllYearsData(ctx)
+	if err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to read insights: %v", err)
+	}
+	sort.Slice(insights, func(i, j int) bool { return insights[i].Year < insights[j].Year })
+
+	manifest := Manifest{CreatedAt: time.Now().UTC().Truncate(time.Second), Format: opts.Format}
+	var observationData, insightData bytes.Buffer
+	if err := WriteObservations(&observationData, opts.Format, observations); err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to encode observations: %v", err)
+	}
+	if err := WriteInsights(&insightData, opts.Format, insights); err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to encode insights: %v", err)
+	}
+	observationTable := describe("observations", opts.Format, len(observations), observationData.Bytes())
+	observationTable.Sources, observationTable.From, observationTable.To = observationRange(observations)
+	insightTable := describe("insights", opts.Format, len(insights), insightData.Bytes())
+	if len(insights) > 0 {
+		insightTable.From = strconv.Itoa(insights[0].Year)
+		insightTable.To = strconv.Itoa(insights[len(insights)-1].Year)
+	}
+	manifest.Tables = []Table{observationTable, insightTable}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	name := archivePrefix + manifest.CreatedAt.Format(timestampLayout) + archiveSuffix
+	path := filepath.Join(opts.Dir, name)
+	checksum, err := writeArchive(path, manifest.CreatedAt, []archiveFile{
+		{ManifestFile, manifestData},
+		{observationTable.File, observationData.Bytes()},
+		{insightTable.File, insightData.Bytes()},
+	})
+	if err != nil {
+		return "", Manifest{}, err
+	}
+	if err := os.WriteFile(path+checksumSuffix, []byte(checksum+"  "+name+"\n"), 0o644); err != nil {
+		return "", Manifest{}, fmt.Errorf("failed to write checksum file: %v", err)
+	}
+
+	if err := prune(opts.Dir, opts.Keep); err != nil {
+		slog.WarnContext(ctx, "Error deleting old snapshots", "dir", opts.Dir, "error", err)
+	}
+	return path, manifest, nil
+}
+
+// describe returns the manifest entry for a table file.
+func describe(name, format string, rows int, data []byte) Table {
+	sum := sha256.Sum256(data)
+	return Table{Name: name, File: name + "." + format, Rows: rows, Bytes: len(data), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// observationRange returns the distinct sources and the first and last dates of observations.
+func observationRange(observations []dto.Observation) (sources []string, from, to string) {
+	if len(observations) == 0 {
+		return nil, "", ""
+	}
+	seen := make(map[string]bool)
+	first, last := observations[0].Date, observations[0].Date
+	for _, o := range observations {
+		if !seen[o.Source] {
+			seen[o.Source] = true
+			sources = append(sources, o.Source)
+		}
+		if o.Date.Before(first) {
+			first = o.Date
+		}
+		if o.Date.After(last) {
+			last = o.Date
+		}
+	}
+	sort.Strings(sources)
+	return sources, first.Format("2006-01-02"), last.Format("2006-01-02")
+}
+
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// writeArchive writes files to a gzip-compressed tar archive at path and returns the
+// hex-encoded SHA-256 of the archive. The archive is written to a temporary file first,
+// so readers never see a partial archive, and is never moved over an existing file:
+// archive names have one-second resolution, so a second snapshot within the same second fails.
+func writeArchive(path string, modTime time.Time, files []archiveFile) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(tmp, hash))
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), ModTime: modTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return "", fmt.Errorf("failed to write archive: %v", err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return "", fmt.Errorf("failed to write archive: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write archive: %v", err)
+	}
+	// Link, unlike Rename, fails if path already exists.
+	if err := os.Link(tmp.Name(), path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("snapshot %s already exists", filepath.Base(path))
+		}
+		return "", fmt.Errorf("failed to write archive: %v", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// List returns the paths of the snapshot archives in dir, oldest first.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var archives []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), archivePrefix) && strings.HasSuffix(e.Name(), archiveSuffix) {
+			archives = append(archives, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(archives)
+	return archives, nil
+}
+
+// prune deletes all but the newest keep archives and their checksum files. keep 0 keeps all.
+func prune(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	archives, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for len(archives) > keep {
+		if err := os.Remove(archives[0]); err != nil {
+			return err
+		}
+		if err := os.Remove(archives[0] + checksumSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		archives = archives[1:]
+	}
+	return nil
+}
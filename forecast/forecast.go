@@ -0,0 +1,151 @@
+// Package forecast projects the monthly rate ahead with simple statistical models, with
+// prediction intervals, and measures their accuracy by backtesting on past observations.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// Models accepted by Project and Backtest.
+const (
+	ModelNaive  = "naive"
+	ModelDrift  = "drift"
+	ModelLinear = "linear"
+	ModelHolt   = "holt"
+)
+
+// Models lists the supported models.
+var Models = []string{ModelNaive, ModelDrift, ModelLinear, ModelHolt}
+
+// MaxHorizon is the furthest a forecast may look ahead, in months.
+const MaxHorizon = 120
+
+// ErrInsufficientData is returned when there are too few observations to fit a model.
+var ErrInsufficientData = errors.New("not enough observations")
+
+// z-scores of the two-sided 80% and 95% prediction intervals of a normal distribution.
+const (
+	z80 = 1.2815515655446004
+	z95 = 1.959963984540054
+)
+
+// model is a fitted forecasting model.
+type model struct {
+	params map[string]float64 // Fitted parameters, reported with the forecast.
+	// predict returns the point forecast h months after the last observation and its
+	// standard error.
+	predict func(h int) (mean, se float64)
+}
+
+// fitter fits a model to a series of rates, oldest first.
+type fitter struct {
+	minObservations int
+	fit             func(y []float64) model
+}
+
+var fitters = map[string]fitter{
+	ModelNaive:  {2, fitNaive},
+	ModelDrift:  {3, fitDrift},
+	ModelLinear: {3, fitLinear},
+	ModelHolt:   {4, fitHolt},
+}
+
+// Project fits model to observations sorted by date and forecasts the next horizon months,
+// with 80% and 95% prediction intervals. It also returns the fitted parameters.
+func Project(observations []dto.Observation, name string, horizon int) ([]dto.ForecastPoint, map[string]float64, error) {
+	f, err := lookup(name, horizon)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(observations) < f.minObservations {
+		return nil, nil, fmt.Errorf("%w: the %s model needs at least %d, got %d", ErrInsufficientData, name, f.minObservations, len(observations))
+	}
+	m := f.fit(rates(observations))
+	last := observations[len(observations)-1].Date
+	points := make([]dto.ForecastPoint, horizon)
+	for h := 1; h <= horizon; h++ {
+		mean, se := m.predict(h)
+		points[h-1] = dto.ForecastPoint{
+			Date:    monthsAfter(last, h).Format("2006-01-02"),
+			Value:   mean,
+			Lower80: mean - z80*se,
+			Upper80: mean + z80*se,
+			Lower95: mean - z95*se,
+			Upper95: mean + z95*se,
+		}
+	}
+	return points, m.params, nil
+}
+
+// Backtest measures how well model would have forecast the last horizon observations:
+// it fits the model to the observations before them and compares the forecasts with
+// what was observed.
+func Backtest(observations []dto.Observation, name string, horizon int) (dto.ForecastAccuracy, error) {
+	f, err := lookup(name, horizon)
+	if err != nil {
+		return dto.ForecastAccuracy{}, err
+	}
+	train := len(observations) - horizon
+	if train < f.minObservations {
+		return dto.ForecastAccuracy{}, fmt.Errorf("%w: backtesting the %s model over %d months needs at least %d, got %d",
+			ErrInsufficientData, name, horizon, f.minObservations+horizon, len(observations))
+	}
+	m := f.fit(rates(observations[:train]))
+	var absolute, squared, percentage float64
+	var percentages int
+	for h := 1; h <= horizon; h++ {
+		actual := observations[train+h-1].Value
+		mean, _ := m.predict(h)
+		e := actual - mean
+		absolute += math.Abs(e)
+		squared += e * e
+		// Percentage errors are undefined for a zero rate, as in 2009-2015.
+		if actual != 0 {
+			percentage += math.Abs(e / actual)
+			percentages++
+		}
+	}
+	accuracy := dto.ForecastAccuracy{
+		Origin: observations[train-1].Date.Format("2006-01-02"),
+		Months: horizon,
+		MAE:    absolute / float64(horizon),
+		RMSE:   math.Sqrt(squared / float64(horizon)),
+	}
+	if percentages > 0 {
+		accuracy.MAPE = percentage / float64(percentages) * 100
+	}
+	return accuracy, nil
+}
+
+func lookup(name string, horizon int) (fitter, error) {
+	f, ok := fitters[name]
+	if !ok {
+		return fitter{}, fmt.Errorf("model must be one of %s, got %q", strings.Join(Models, ", "), name)
+	}
+	if horizon < 1 || horizon > MaxHorizon {
+		return fitter{}, fmt.Errorf("horizon must be between 1 and %d, got %d", MaxHorizon, horizon)
+	}
+	return f, nil
+}
+
+func rates(observations []dto.Observation) []float64 {
+	y := make([]float64, len(observations))
+	for i, o := range observations {
+		y[i] = o.Value
+	}
+	return y
+}
+
+// monthsAfter returns the same day of the month n months after date, clamped to the
+// end of shorter months.
+func monthsAfter(date time.Time, n int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(n), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
+}
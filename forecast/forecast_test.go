@@ -0,0 +1,227 @@
+package forecast
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// series returns monthly observations starting in January 2024.
+func series(values ...float64) []dto.Observation {
+	observations := make([]dto.Observation, len(values))
+	for i, v := range values {
+		observations[i] = dto.Observation{Date: time.Date(2024, time.January+time.Month(i), 1, 0, 0, 0, 0, time.UTC), Value: v}
+	}
+	return observations
+}
+
+func approx(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestProject(t *testing.T) {
+	linear := series(1, 1.5, 2, 2.5, 3, 3.5) // 1 + 0.5t, ending June 2024.
+	tests := []struct {
+		name       string
+		obs        []dto.Observation
+		model      string
+		horizon    int
+		want       []float64 // Point forecasts.
+		wantSE     []float64 // Standard errors, recovered from the 95% interval.
+		wantParams map[string]float64
+	}{
+		{
+			name: "naive repeats the last rate", obs: linear, model: ModelNaive, horizon: 3,
+			want:   []float64{3.5, 3.5, 3.5},
+			wantSE: []float64{0.5, 0.5 * math.Sqrt2, 0.5 * math.Sqrt(3)},
+		},
+		{
+			name: "naive on a constant series is certain", obs: series(2, 2, 2), model: ModelNaive, horizon: 2,
+			want:   []float64{2, 2},
+			wantSE: []float64{0, 0},
+		},
+		{
+			name: "drift extends the average change", obs: linear, model: ModelDrift, horizon: 2,
+			want:       []float64{4, 4.5},
+			wantSE:     []float64{0, 0},
+			wantParams: map[string]float64{"drift": 0.5},
+		},
+		{
+			name: "linear fits the line exactly", obs: linear, model: ModelLinear, horizon: 2,
+			want:       []float64{4, 4.5},
+			wantSE:     []float64{0, 0},
+			wantParams: map[string]float64{"intercept": 1, "slope": 0.5},
+		},
+		{
+			name: "linear through noise", obs: series(1, 3, 2, 4), model: ModelLinear, horizon: 1,
+			// Least squares: slope 0.8, intercept 1.3; residuals -0.3, 0.9, -0.9, 0.3.
+			want:       []float64{4.5},
+			wantSE:     []float64{math.Sqrt(1.8/2) * math.Sqrt(1+1.0/4+(4-1.5)*(4-1.5)/5)},
+			wantParams: map[string]float64{"intercept": 1.3, "slope": 0.8},
+		},
+		{
+			name: "holt is exact on a line", obs: linear, model: ModelHolt, horizon: 2,
+			want:   []float64{4, 4.5},
+			wantSE: []float64{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, params, err := Project(tt.obs, tt.model, tt.horizon)
+			if err != nil {
+				t.Fatalf("Project() error = %v", err)
+			}
+			if len(points) != tt.horizon {
+				t.Fatalf("got %d points, want %d", len(points), tt.horizon)
+			}
+			last := tt.obs[len(tt.obs)-1].Date
+			for i, p := range points {
+				if want := last.AddDate(0, i+1, 0).Format("2006-01-02"); p.Date != want {
+					t.Errorf("point %d date = %s, want %s", i, p.Date, want)
+				}
+				if !approx(p.Value, tt.want[i]) {
+					t.Errorf("point %d value = %g, want %g", i, p.Value, tt.want[i])
+				}
+				if se := (p.Upper95 - p.Value) / z95; !approx(se, tt.wantSE[i]) {
+					t.Errorf("point %d standard error = %g, want %g", i, se, tt.wantSE[i])
+				}
+				if !approx(p.Value-p.Lower80, p.Upper80-p.Value) || !approx((p.Upper80-p.Value)*z95, (p.Upper95-p.Value)*z80) {
+					t.Errorf("point %d intervals are not symmetric normal intervals: %+v", i, p)
+				}
+			}
+			for name, want := range tt.wantParams {
+				if got, ok := params[name]; !ok || !approx(got, want) {
+					t.Errorf("param %s = %g, want %g", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectHoltParams(t *testing.T) {
+	// A noisy series, so the parameters are chosen by the grid search.
+	_, params, err := Project(series(5.3, 5.3, 5.1, 4.8, 4.6, 4.6, 4.3, 4.1, 4.3, 4.5), ModelHolt, 1)
+	if err != nil {
+		t.Fatalf("Project() error = %v", err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		v := params[name]
+		if v < 0.01 || v > 1 || v*100 != math.Round(v*100) {
+			t.Errorf("%s = %v, want a multiple of 0.01 in [0.01, 1]", name, v)
+		}
+	}
+}
+
+func TestProjectErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		obs          []dto.Observation
+		model        string
+		horizon      int
+		insufficient bool
+	}{
+		{name: "unknown model", obs: series(1, 2, 3, 4), model: "arima", horizon: 1},
+		{name: "zero horizon", obs: series(1, 2, 3, 4), model: ModelNaive, horizon: 0},
+		{name: "horizon too far", obs: series(1, 2, 3, 4), model: ModelNaive, horizon: MaxHorizon + 1},
+		{name: "naive needs 2", obs: series(1), model: ModelNaive, horizon: 1, insufficient: true},
+		{name: "drift needs 3", obs: series(1, 2), model: ModelDrift, horizon: 1, insufficient: true},
+		{name: "linear needs 3", obs: series(1, 2), model: ModelLinear, horizon: 1, insufficient: true},
+		{name: "holt needs 4", obs: series(1, 2, 3), model: ModelHolt, horizon: 1, insufficient: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Project(tt.obs, tt.model, tt.horizon)
+			if err == nil {
+				t.Fatal("Project() succeeded, want an error")
+			}
+			if got := errors.Is(err, ErrInsufficientData); got != tt.insufficient {
+				t.Errorf("errors.Is(%v, ErrInsufficientData) = %v, want %v", err, got, tt.insufficient)
+			}
+		})
+	}
+}
+
+func TestBacktest(t *testing.T) {
+	tests := []struct {
+		name    string
+		obs     []dto.Observation
+		model   string
+		horizon int
+		want    dto.ForecastAccuracy
+	}{
+		{
+			name: "exact model", obs: series(1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5), model: ModelLinear, horizon: 2,
+			want: dto.ForecastAccuracy{Origin: "2024-06-01", Months: 2},
+		},
+		{
+			name: "naive on a rising series", obs: series(1, 2, 3, 4, 5), model: ModelNaive, horizon: 2,
+			// Forecast 3 for actuals 4 and 5: errors 1 and 2.
+			want: dto.ForecastAccuracy{Origin: "2024-03-01", Months: 2, MAE: 1.5, RMSE: math.Sqrt(2.5), MAPE: (1.0/4 + 2.0/5) / 2 * 100},
+		},
+		{
+			name: "zero actuals are left out of MAPE", obs: series(2, 2, 0, 1), model: ModelNaive, horizon: 2,
+			// Forecast 2 for actuals 0 and 1: errors 2 and 1, a percentage error only for 1.
+			want: dto.ForecastAccuracy{Origin: "2024-02-01", Months: 2, MAE: 1.5, RMSE: math.Sqrt(2.5), MAPE: 100},
+		},
+		{
+			name: "MAPE is 0 when every actual is zero", obs: series(1, 1, 0, 0), model: ModelNaive, horizon: 2,
+			want: dto.ForecastAccuracy{Origin: "2024-02-01", Months: 2, MAE: 1, RMSE: 1},
+		},
+		{
+			name: "drift", obs: series(1, 2, 3, 5), model: ModelDrift, horizon: 1,
+			// Fitted to 1, 2, 3: forecast 4 for an actual of 5.
+			want: dto.ForecastAccuracy{Origin: "2024-03-01", Months: 1, MAE: 1, RMSE: 1, MAPE: 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Backtest(tt.obs, tt.model, tt.horizon)
+			if err != nil {
+				t.Fatalf("Backtest() error = %v", err)
+			}
+			if got.Origin != tt.want.Origin || got.Months != tt.want.Months ||
+				!approx(got.MAE, tt.want.MAE) || !approx(got.RMSE, tt.want.RMSE) || !approx(got.MAPE, tt.want.MAPE) {
+				t.Errorf("Backtest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBacktestInsufficientData(t *testing.T) {
+	// Holt needs 4 observations to fit, plus the 3 held out.
+	_, err := Backtest(series(1, 2, 3, 4, 5, 6), ModelHolt, 3)
+	if !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("Backtest() error = %v, want ErrInsufficientData", err)
+	}
+	if _, err := Backtest(series(1, 2, 3, 4, 5, 6, 7), ModelHolt, 3); err != nil {
+		t.Errorf("Backtest() with enough observations: %v", err)
+	}
+}
+
+func TestMonthsAfter(t *testing.T) {
+	tests := []struct {
+		date string
+		n    int
+		want string
+	}{
+		{"2024-01-01", 1, "2024-02-01"},
+		{"2024-11-01", 3, "2025-02-01"},
+		{"2024-01-31", 1, "2024-02-29"}, // Clamped to the end of February in a leap year.
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-03-31", 1, "2024-04-30"},
+		{"2024-01-31", 2, "2024-03-31"},
+		{"2024-06-15", 120, "2034-06-15"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tt.date)
+			if got := monthsAfter(date, tt.n).Format("2006-01-02"); got != tt.want {
+				t.Errorf("monthsAfter(%s, %d) = %s, want %s", tt.date, tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package forecast
+
+import "math"
+
+// The prediction intervals below assume normally distributed, uncorrelated one-step errors
+// and follow the usual formulas for each model (see Hyndman and Athanasopoulos, Forecasting:
+// Principles and Practice, section 5.5 and chapter 8).
+
+// fitNaive forecasts the last observed rate. The standard error grows with sqrt(h), as for
+// a random walk.
+func fitNaive(y []float64) model {
+	last := y[len(y)-1]
+	var squares float64
+	for t := 1; t < len(y); t++ {
+		e := y[t] - y[t-1]
+		squares += e * e
+	}
+	sigma := math.Sqrt(squares / float64(len(y)-1))
+	return model{
+		predict: func(h int) (float64, float64) {
+			return last, sigma * math.Sqrt(float64(h))
+		},
+	}
+}
+
+// fitDrift extends the line from the first to the last observation: the last rate plus
+// the average monthly change.
+func fitDrift(y []float64) model {
+	n := len(y)
+	last := y[n-1]
+	drift := (last - y[0]) / float64(n-1)
+	var squares float64
+	for t := 1; t < n; t++ {
+		e := y[t] - y[t-1] - drift
+		squares += e * e
+	}
+	sigma := math.Sqrt(squares / float64(n-2))
+	return model{
+		params: map[string]float64{"drift": drift},
+		predict: func(h int) (float64, float64) {
+			fh := float64(h)
+			return last + fh*drift, sigma * math.Sqrt(fh*(1+fh/float64(n-1)))
+		},
+	}
+}
+
+// fitLinear fits a least-squares line through all observations against their index.
+func fitLinear(y []float64) model {
+	n := len(y)
+	xMean := float64(n-1) / 2
+	yMean := 0.0
+	for _, v := range y {
+		yMean += v
+	}
+	yMean /= float64(n)
+	var sxx, sxy float64
+	for t, v := range y {
+		dx := float64(t) - xMean
+		sxx += dx * dx
+		sxy += dx * (v - yMean)
+	}
+	slope := sxy / sxx
+	intercept := yMean - slope*xMean
+	var squares float64
+	for t, v := range y {
+		e := v - (intercept + slope*float64(t))
+		squares += e * e
+	}
+	s := math.Sqrt(squares / float64(n-2))
+	return model{
+		params: map[string]float64{"intercept": intercept, "slope": slope},
+		predict: func(h int) (float64, float64) {
+			x := float64(n - 1 + h)
+			return intercept + slope*x, s * math.Sqrt(1+1/float64(n)+(x-xMean)*(x-xMean)/sxx)
+		},
+	}
+}
+
+// fitHolt fits Holt's linear exponential smoothing, which tracks a level and a trend:
+//
+//	e[t] = y[t] - (level + trend)
+//	level = level + trend + alpha*e[t]
+//	trend = trend + alpha*beta*e[t]
+//
+// starting from level y[0] and trend y[1]-y[0]. alpha and beta are chosen to minimize the
+// sum of squared one-step errors, first on a coarse grid and then on a finer one around
+// the best point.
+func fitHolt(y []float64) model {
+	best := holtState{sse: math.Inf(1)}
+	// Grid points are counted in hundredths so that parameters are exact to two decimals.
+	search := func(alphaFrom, alphaTo, betaFrom, betaTo, step int) {
+		for a := alphaFrom; a <= alphaTo; a += step {
+			for b := betaFrom; b <= betaTo; b += step {
+				if s := smoothHolt(y, float64(a)/100, float64(b)/100); s.sse < best.sse {
+					best = s
+				}
+			}
+		}
+	}
+	search(5, 100, 5, 100, 5)
+	alpha, beta := int(math.Round(best.alpha*100)), int(math.Round(best.beta*100))
+	search(max(alpha-4, 1), min(alpha+4, 100), max(beta-4, 1), min(beta+4, 100), 1)
+
+	// The first error is always 0, as the initial trend makes the first forecast exact.
+	sigma := math.Sqrt(best.sse / float64(len(y)-2))
+	return model{
+		params: map[string]float64{"alpha": best.alpha, "beta": best.beta},
+		predict: func(h int) (float64, float64) {
+			variance := 1.0
+			for j := 1; j < h; j++ {
+				c := best.alpha * (1 + float64(j)*best.beta)
+				variance += c * c
+			}
+			return best.level + float64(h)*best.trend, sigma * math.Sqrt(variance)
+		},
+	}
+}
+
+// holtState is the result of smoothing a series with given parameters.
+type holtState struct {
+	alpha, beta  float64
+	level, trend float64 // Level and trend after the last observation.
+	sse          float64 // Sum of squared one-step errors.
+}
+
+func smoothHolt(y []float64, alpha, beta float64) holtState {
+	s := holtState{alpha: alpha, beta: beta, level: y[0], trend: y[1] - y[0]}
+	for t := 1; t < len(y); t++ {
+		e := y[t] - (s.level + s.trend)
+		s.sse += e * e
+		s.level += s.trend + alpha*e
+		s.trend += alpha * beta * e
+	}
+	return s
+}
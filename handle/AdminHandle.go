@@ -0,0 +1,119 @@
+package handle
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/jobs"
+	"federal-funds-rate-metrics-ByYear/metrics"
+)
+
+var jobQueue *jobs.Queue
+
+// InitJobs sets the job queue used by the admin handlers.
+func InitJobs(queue *jobs.Queue) {
+	jobQueue = queue
+}
+
+// RequireAdmin guards the /admin endpoints. When an admin token is configured, requests
+// must send it as "Authorization: Bearer <token>"; without one, only clients connecting
+// from a loopback address are let through. The token is read on every request, so a
+// reload that changes it takes effect immediately.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var token string
+		if current := appConfig.Load(); current != nil {
+			token = current.AdminToken
+		}
+		if token == "" {
+			if !isLoopback(r.RemoteAddr) {
+				writeError(w, r, http.StatusForbidden, CodeForbidden, "Admin endpoints only answer localhost unless ADMIN_TOKEN is set", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "A valid admin bearer token is required", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether a request's remote address is a loopback address.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Jobs handles /admin/jobs.
+// GET lists recent jobs with their status; POST /admin/jobs?kind=<kind> enqueues a job.
+func Jobs(w http.ResponseWriter, r *http.Request) {
+	if jobQueue == nil {
+		handleError(w, r, errors.New("job queue not initialized"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"status": "success",
+			"kinds":  jobQueue.Kinds(),
+			"data":   jobQueue.Jobs(),
+		})
+	case http.MethodPost:
+		job, err := jobQueue.Submit(r.URL.Query().Get("kind"))
+		if err != nil {
+			handleError(w, r, err)
+			return
+		}
+		respondWithJSON(w, http.StatusAccepted, map[string]interface{}{
+			"status": "success",
+			"data":   job,
+		})
+	default:
+		methodNotAllowed(w, r, "GET, POST")
+	}
+}
+
+// SLOs handles GET /admin/slo, listing every objective with its error budget and burn rates.
+func SLOs(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"data":   metrics.SLOStatuses(),
+	})
+}
+
+// ConfigDump handles GET /admin/config, listing every setting with its effective value
+// and where it came from. Secrets are redacted.
+func ConfigDump(w http.ResponseWriter, r *http.Request) {
+	current := appConfig.Load()
+	if current == nil {
+		handleError(w, r, errors.New("config not initialized"))
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "success",
+		"file":   current.File,
+		"data":   current.Settings(),
+	})
+}
+
+// DashboardTitle is the title of the generated Grafana dashboard.
+const DashboardTitle = "Federal Funds Rate Metrics"
+
+// Dashboard handles GET /admin/dashboard, returning a Grafana dashboard JSON model
+// generated from the registered metrics, ready to import.
+func Dashboard(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, metrics.GrafanaDashboard(DashboardTitle))
+}
@@ -0,0 +1,84 @@
+package handle
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/analytics"
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/services"
+)
+
+// defaultRollingWindow is the window of GET /analytics/rolling when none is given: one year.
+const defaultRollingWindow = 12
+
+// RollingStatistics handles GET /analytics/rolling?window=<n>&stat=<mean|stddev|min|max|ewma>&alpha=<a>,
+// computing a statistic over the last window monthly observations at each observation, oldest first.
+// window defaults to 12 and stat to mean. For ewma, window sets the span (alpha = 2/(window+1))
+// unless alpha is given.
+func RollingStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+
+	query := r.URL.Query()
+	window := defaultRollingWindow
+	if value := query.Get("window"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "window must be a whole number of at least 1",
+				[]dto.FieldError{{Field: "window", Problem: "min"}})
+			return
+		}
+		window = n
+	}
+	stat := strings.ToLower(query.Get("stat"))
+	if stat == "" {
+		stat = analytics.StatMean
+	}
+	if !slices.Contains(analytics.Stats, stat) {
+		writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "stat must be one of "+strings.Join(analytics.Stats, ", "),
+			[]dto.FieldError{{Field: "stat", Problem: "oneof"}})
+		return
+	}
+	var alpha float64
+	if value := query.Get("alpha"); value != "" {
+		if stat != analytics.StatEWMA {
+			writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "alpha is only used with stat=ewma",
+				[]dto.FieldError{{Field: "alpha", Problem: "unexpected"}})
+			return
+		}
+		a, err := strconv.ParseFloat(value, 64)
+		if err != nil || !(a > 0 && a <= 1) {
+			writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "alpha must be greater than 0 and at most 1",
+				[]dto.FieldError{{Field: "alpha", Problem: "range"}})
+			return
+		}
+		alpha = a
+	}
+	if stat == analytics.StatEWMA && alpha == 0 {
+		alpha = analytics.SpanAlpha(window)
+	}
+
+	observations, err := services.GetObservations(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	points, err := analytics.Compute(observations, stat, window, alpha)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.MessageRolling{
+		Status: "success",
+		Stat:   stat,
+		Window: window,
+		Alpha:  alpha,
+		Data:   points,
+	})
+}
@@ -1,16 +1,20 @@
 package handle
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sort"
+	"sync/atomic"
 	"time"
 
+	"federal-funds-rate-metrics-ByYear/appmetrics"
 	"federal-funds-rate-metrics-ByYear/config"
 	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/redact"
 	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
 )
 
 // FederalFundsHandlerInsight handles requests for federal funds insights, either retrieving them from the database
@@ -19,17 +23,17 @@ func FederalFundsHandlerInsight(w http.ResponseWriter, r *http.Request) {
 	currentYear := time.Now().Year() - 1
 
 	// Check if current year's data exists in the database
-	exists, err := services.IsCurrentYearDataPresent(currentYear)
+	exists, err := services.IsCurrentYearDataPresent(r.Context(), currentYear)
 	if err != nil {
-		handleError(w, fmt.Sprintf("Error checking current year data: %v", err))
+		handleError(w, r, fmt.Errorf("error checking current year data: %w", err))
 		return
 	}
 
 	if exists {
 		// Retrieve all years' data from the database
-		insights, err := services.GetAllYearsData()
+		insights, err := services.GetAllYearsData(r.Context())
 		if err != nil {
-			handleError(w, fmt.Sprintf("Error retrieving data: %v", err))
+			handleError(w, r, fmt.Errorf("error retrieving data: %w", err))
 			return
 		}
 
@@ -42,23 +46,28 @@ func FederalFundsHandlerInsight(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch data from the external API
-	data, err := fetchFederalFundsRate()
+	data, err := fetchFederalFundsRate(r.Context())
 	if err != nil {
-		handleError(w, fmt.Sprintf("Error fetching data: %v", err))
-		return
-	}
-
-	// Process the data to calculate insights
-	insights, err := services.ProcessFederalFundsData(data)
-	if err != nil {
-		handleError(w, fmt.Sprintf("Error processing data: %v", err))
+		appmetrics.RecordRefresh(false)
+		// Serve whatever is stored, flagged as stale, rather than failing outright.
+		insights, dbErr := services.GetAllYearsData(r.Context())
+		if dbErr == nil && len(insights) > 0 {
+			respondWithJSON(w, http.StatusOK, dto.MessageInsights{
+				Status:  "success",
+				Message: fmt.Sprintf("Upstream unavailable (%v); serving stored data.", redact.Error(err)),
+				Stale:   true,
+				Data:    insights,
+			})
+			return
+		}
+		handleError(w, r, fmt.Errorf("error fetching data: %w", err))
 		return
 	}
 
-	// Store the insights in the database
-	err = services.StoreFederalFundsInsights(insights)
+	// Store the observations, then calculate and store insights from them
+	insights, err := services.RefreshInsights(r.Context(), data, source.Name)
 	if err != nil {
-		handleError(w, fmt.Sprintf("Error storing insights: %v", err))
+		handleError(w, r, fmt.Errorf("error refreshing insights: %w", err))
 		return
 	}
 
@@ -72,16 +81,16 @@ func FederalFundsHandlerInsight(w http.ResponseWriter, r *http.Request) {
 
 // FederalFundsHandler handles simple requests to fetch federal funds data directly from the API.
 func FederalFundsHandler(w http.ResponseWriter, r *http.Request) {
-	data, err := fetchFederalFundsRate()
+	data, err := fetchFederalFundsRate(r.Context())
 	if err != nil {
-		handleError(w, fmt.Sprintf("Error fetching federal funds rate: %v", err))
+		handleError(w, r, fmt.Errorf("error fetching federal funds rate: %w", err))
 		return
 	}
 
 	// Process the data to calculate insights
 	insights, err := services.ProcessFederalFundsData(data)
 	if err != nil {
-		handleError(w, fmt.Sprintf("Error processing data: %v", err))
+		handleError(w, r, fmt.Errorf("error processing data: %w", err))
 		return
 	}
 
@@ -98,40 +107,14 @@ func FederalFundsHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// fetchFederalFundsRate fetches federal funds rate data from the Alpha Vantage API.
-func fetchFederalFundsRate() (dto.AlphaVantageResponse, error) {
-	if appConfig == nil {
+// fetchFederalFundsRate fetches federal funds rate data from the Alpha Vantage API
+// through the shared upstream client.
+func fetchFederalFundsRate(ctx context.Context) (dto.AlphaVantageResponse, error) {
+	client := upstream.Load()
+	if client == nil {
 		return dto.AlphaVantageResponse{}, fmt.Errorf("config not initialized")
 	}
-
-	apiURL := fmt.Sprintf("https://www.alphavantage.co/query?function=FEDERAL_FUNDS_RATE&interval=monthly&apikey=%s", appConfig.APIKey)
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return dto.AlphaVantageResponse{}, fmt.Errorf("failed to fetch data: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return dto.AlphaVantageResponse{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return dto.AlphaVantageResponse{}, fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	var data dto.AlphaVantageResponse
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return dto.AlphaVantageResponse{}, fmt.Errorf("failed to parse JSON: %v", err)
-	}
-
-	return data, nil
-}
-
-// handleError is a helper function to send error responses to the client.
-func handleError(w http.ResponseWriter, message string) {
-	http.Error(w, message, http.StatusInternalServerError)
+	return client.FetchFederalFundsRate(ctx)
 }
 
 // respondWithJSON is a helper function to send JSON responses.
@@ -141,9 +124,15 @@ func respondWithJSON(w http.ResponseWriter, status int, response interface{}) {
 	json.NewEncoder(w).Encode(response)
 }
 
-var appConfig *config.Config
+// appConfig is the current configuration. It is replaced when the configuration is reloaded.
+var appConfig atomic.Pointer[config.Config]
+
+// upstream is the client used to reach Alpha Vantage. Reloads update its settings in place.
+var upstream atomic.Pointer[source.Client]
 
-// InitConfig initializes the configuration for the handler package.
-func InitConfig(config *config.Config) {
-	appConfig = config
+// InitConfig initializes the configuration and upstream client for the handler package.
+// It is called again with the new configuration after each reload.
+func InitConfig(config *config.Config, client *source.Client) {
+	appConfig.Store(config)
+	upstream.Store(client)
 }
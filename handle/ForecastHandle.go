@@ -0,0 +1,77 @@
+package handle
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/forecast"
+	"federal-funds-rate-metrics-ByYear/services"
+)
+
+// defaultForecastHorizon is the horizon of GET /forecast when none is given: one year.
+const defaultForecastHorizon = 12
+
+// Forecast handles GET /forecast?model=<naive|drift|linear|holt>&horizon=<months>, projecting
+// the monthly rate horizon months past the last stored observation with 80% and 95%
+// prediction intervals. model defaults to holt and horizon to 12. The response includes the
+// model's errors when backtested over the last horizon observations, if there are enough.
+func Forecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+
+	query := r.URL.Query()
+	model := strings.ToLower(query.Get("model"))
+	if model == "" {
+		model = forecast.ModelHolt
+	}
+	if !slices.Contains(forecast.Models, model) {
+		writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "model must be one of "+strings.Join(forecast.Models, ", "),
+			[]dto.FieldError{{Field: "model", Problem: "oneof"}})
+		return
+	}
+	horizon := defaultForecastHorizon
+	if value := query.Get("horizon"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > forecast.MaxHorizon {
+			writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, fmt.Sprintf("horizon must be a number of months between 1 and %d", forecast.MaxHorizon),
+				[]dto.FieldError{{Field: "horizon", Problem: "range"}})
+			return
+		}
+		horizon = n
+	}
+
+	observations, err := services.GetObservations(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	points, params, err := forecast.Project(observations, model, horizon)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+	response := dto.MessageForecast{
+		Status:     "success",
+		Model:      model,
+		Horizon:    horizon,
+		Parameters: params,
+		Data:       points,
+	}
+	accuracy, err := forecast.Backtest(observations, model, horizon)
+	switch {
+	case err == nil:
+		response.Backtest = &accuracy
+	case !errors.Is(err, forecast.ErrInsufficientData):
+		handleError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,51 @@
+package handle
+
+import (
+	"net/http"
+
+	"federal-funds-rate-metrics-ByYear/health"
+	"federal-funds-rate-metrics-ByYear/metrics"
+)
+
+var livenessChecks, readinessChecks *health.Checker
+
+// InitHealth sets the checkers used by the liveness and readiness probes.
+func InitHealth(liveness, readiness *health.Checker) {
+	livenessChecks = liveness
+	readinessChecks = readiness
+}
+
+// Liveness handles GET /livez. It only runs the liveness checks, which look at the
+// process itself (such as stuck job workers), so a lost database connection does not
+// get the process restarted.
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	respondWithReport(w, r, livenessChecks)
+}
+
+// Readiness handles GET /readyz, returning 503 when a critical dependency check fails
+// so the pod is taken out of rotation. The outcome is recorded for availability tracking.
+func Readiness(w http.ResponseWriter, r *http.Request) {
+	report := respondWithReport(w, r, readinessChecks)
+	metrics.RecordHealthProbe(report.Healthy())
+}
+
+// HealthCheck handles GET /health. It runs the readiness checks and records the
+// outcome for availability tracking.
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+	report := respondWithReport(w, r, readinessChecks)
+	metrics.RecordHealthProbe(report.Healthy())
+}
+
+// respondWithReport runs the checks and writes the report with 200 or 503.
+func respondWithReport(w http.ResponseWriter, r *http.Request, checker *health.Checker) health.Report {
+	report := health.Report{Status: health.StatusOK}
+	if checker != nil {
+		report = checker.Run(r.Context())
+	}
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+	respondWithJSON(w, status, report)
+	return report
+}
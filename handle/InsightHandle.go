@@ -0,0 +1,54 @@
+package handle
+
+import (
+	"net/http"
+	"strconv"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/services"
+)
+
+// PeriodInsights handles GET /insights?period=<quarter|half|year|decade>&fiscal_start=<1-12>,
+// computing insights per period from the stored observations, newest first.
+// period defaults to year and fiscal_start to 1 (calendar years).
+func PeriodInsights(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, "GET")
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("period")
+	if kind == "" {
+		kind = services.PeriodYear
+	}
+	fiscalStart := 0
+	if value := query.Get("fiscal_start"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 12 {
+			writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "fiscal_start must be a month number between 1 and 12",
+				[]dto.FieldError{{Field: "fiscal_start", Problem: "range"}})
+			return
+		}
+		fiscalStart = n
+	}
+	period, err := services.ParsePeriod(kind, fiscalStart)
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, err.Error(),
+			[]dto.FieldError{{Field: "period", Problem: "oneof"}})
+		return
+	}
+
+	observations, err := services.GetObservations(r.Context())
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.MessagePeriodInsights{
+		Status:           "success",
+		Period:           period.Kind,
+		FiscalStartMonth: int(period.FiscalStartMonth),
+		Data:             services.Aggregate(observations, period),
+	})
+}
@@ -19,26 +19,25 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 	var response dto.Message
 	err := json.NewDecoder(r.Body).Decode(&requestDto)
 	if err != nil {
-		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid JSON format", nil)
 		return
 	}
 
 	// Validate DTO
 	err = validate.Struct(requestDto)
 	if err != nil {
-		http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
+		validationFailed(w, r, err)
 		return
 	}
 
-	savedDto, err := services.CreateUser(&requestDto)
+	savedDto, err := services.CreateUser(r.Context(), &requestDto)
 	if err != nil {
-		http.Error(w, "Creation error: "+err.Error(), http.StatusBadRequest)
+		handleError(w, r, err)
 		return
 	}
 
 	response = dto.Message{Status: "success", Data: savedDto}
-	w.WriteHeader(http.StatusCreated)
-	jsonResponse(w, response)
+	respondWithJSON(w, http.StatusCreated, response)
 }
 
 // UserInfo handles GET requests to retrieve user information.
@@ -52,46 +51,23 @@ func UserInfo(w http.ResponseWriter, r *http.Request) {
 		email = r.URL.Path[len(prefix):]
 	}
 
-	var response dto.Message
-
 	if email == "" {
 		// Respond with an error message if email is not provided.
-		response = dto.Message{
-			Status:  "fail",
-			Message: "Invalid email. Please provide a valid email.",
-		}
-	} else {
-		// Fetch user details.
-		details, err := services.GetUserByEmail(email)
-		if err != nil {
-			http.Error(w, "Retrieval error: "+err.Error(), http.StatusBadRequest)
-			return
-		} else if details.ID == 0 {
-			// User not found.
-			response = dto.Message{
-				Status:  "fail",
-				Message: "User not found",
-			}
-		} else {
-			// Success: convert details to DTO.
-			convertedDto := dto.ConvertToUserDto(details)
-			response = dto.Message{
-				Status: "success",
-				Data:   &convertedDto,
-			}
-		}
+		writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "Invalid email. Please provide a valid email.",
+			[]dto.FieldError{{Field: "email", Problem: "required"}})
+		return
 	}
-	jsonResponse(w, response)
-}
 
-// HealthCheck handles GET requests to check server health.
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ok"))
-}
+	// Fetch user details; a missing user maps to 404.
+	details, err := services.GetUserByEmail(r.Context(), email)
+	if err != nil {
+		handleError(w, r, err)
+		return
+	}
 
-// jsonResponse is a utility function to send JSON responses.
-func jsonResponse(w http.ResponseWriter, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(data)
+	convertedDto := dto.ConvertToUserDto(details)
+	respondWithJSON(w, http.StatusOK, dto.Message{
+		Status: "success",
+		Data:   &convertedDto,
+	})
 }
@@ -0,0 +1,90 @@
+package handle
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/forecast"
+	"federal-funds-rate-metrics-ByYear/jobs"
+	"federal-funds-rate-metrics-ByYear/logging"
+	"federal-funds-rate-metrics-ByYear/redact"
+	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes used in dto.ErrorResponse.
+const (
+	CodeBadRequest          = "bad_request"
+	CodeValidationFailed    = "validation_failed"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeInsufficientData    = "insufficient_data"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUnavailable         = "unavailable"
+	CodeInternal            = "internal"
+)
+
+// handleError maps err to a status and error code and writes the error envelope.
+// Domain errors keep their message; anything unrecognised is a 500 whose details
+// are only logged, with the request ID in the response to find them.
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code, message := http.StatusInternalServerError, CodeInternal, "Internal server error"
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		status, code, message = http.StatusNotFound, CodeNotFound, err.Error()
+	case errors.Is(err, services.ErrConflict):
+		status, code, message = http.StatusConflict, CodeConflict, err.Error()
+	case errors.Is(err, forecast.ErrInsufficientData):
+		status, code, message = http.StatusUnprocessableEntity, CodeInsufficientData, err.Error()
+	case errors.Is(err, source.ErrUnavailable):
+		status, code, message = http.StatusServiceUnavailable, CodeUpstreamUnavailable, "Upstream data source unavailable"
+	case errors.Is(err, jobs.ErrUnknownKind):
+		status, code, message = http.StatusBadRequest, CodeBadRequest, err.Error()
+	case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrStopped):
+		status, code, message = http.StatusServiceUnavailable, CodeUnavailable, err.Error()
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(r.Context(), level, "Request failed", "method", r.Method, "path", r.URL.Path, "status", status, "error", err)
+	writeError(w, r, status, code, redact.String(message), nil)
+}
+
+// writeError writes an error envelope with the given status, code and message.
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string, details []dto.FieldError) {
+	respondWithJSON(w, status, dto.ErrorResponse{
+		Status:    "error",
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: logging.RequestID(r.Context()),
+	})
+}
+
+// validationFailed writes a 422 listing every field that failed validation.
+func validationFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var details []dto.FieldError
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		for _, fe := range fieldErrors {
+			details = append(details, dto.FieldError{Field: strings.ToLower(fe.Field()), Problem: fe.Tag()})
+		}
+	}
+	writeError(w, r, http.StatusUnprocessableEntity, CodeValidationFailed, "Request validation failed", details)
+}
+
+// methodNotAllowed writes a 405 listing the allowed methods.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed", nil)
+}
@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/db"
+	"federal-funds-rate-metrics-ByYear/jobs"
+	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
+)
+
+// Database checks that the connection pool can reach Postgres.
+func Database() CheckFunc {
+	return db.Ping
+}
+
+// Migrations checks that the schema is at the newest embedded migration version.
+func Migrations() CheckFunc {
+	return func(ctx context.Context) error {
+		version, err := db.MigrationVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if latest := db.LatestMigration(); version < latest {
+			return fmt.Errorf("schema at migration %d, expected %d", version, latest)
+		}
+		return nil
+	}
+}
+
+// RefreshAge checks that observations were stored within maxAge.
+func RefreshAge(maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		last, err := services.LastRefreshTime(ctx)
+		if err != nil {
+			return err
+		}
+		if last.IsZero() {
+			return fmt.Errorf("no data has been refreshed yet")
+		}
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last refresh was %s ago, more than %s", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
+
+// Circuit checks that the upstream client's circuit breaker is not open.
+func Circuit(client *source.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		if state := client.BreakerState(); state == source.StateOpen {
+			return fmt.Errorf("upstream circuit breaker is %s", state)
+		}
+		return nil
+	}
+}
+
+// Workers checks that the job workers are making progress: it fails when a job has been
+// running for longer than maxRuntime, as happens when a worker is stuck. Jobs waiting in
+// a long queue do not fail it while the workers keep finishing jobs.
+func Workers(queue *jobs.Queue, maxRuntime time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		if started, ok := queue.OldestRunning(); ok {
+			if runtime := time.Since(started); runtime > maxRuntime {
+				return fmt.Errorf("a job has been running for %s, more than %s", runtime.Round(time.Second), maxRuntime)
+			}
+		}
+		return nil
+	}
+}
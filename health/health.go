@@ -0,0 +1,138 @@
+// Package health runs dependency checks for the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/redact"
+)
+
+// Check statuses.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn" // A non-critical check failed.
+	StatusFail = "fail"
+)
+
+// Overall report statuses.
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"    // Only non-critical checks failed; still ready.
+	StatusUnavailable = "unavailable" // A critical check failed; not ready.
+)
+
+// CheckFunc reports a problem with a dependency by returning an error.
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of running every registered check.
+type Report struct {
+	Status    string   `json:"status"`
+	CheckedAt string   `json:"checked_at"`
+	Checks    []Result `json:"checks"`
+}
+
+// Healthy reports whether every critical check passed.
+func (r Report) Healthy() bool {
+	return r.Status != StatusUnavailable
+}
+
+// check is a registered check.
+type check struct {
+	name     string
+	fn       CheckFunc
+	critical bool
+}
+
+// Checker is a registry of named checks, run concurrently with a per-check timeout.
+type Checker struct {
+	mu      sync.Mutex
+	checks  []check
+	timeout time.Duration
+}
+
+// NewChecker creates a Checker giving each check at most timeout to complete.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a check. When a critical check fails the report is unavailable;
+// when a non-critical one fails it is only degraded.
+func (c *Checker) Register(name string, critical bool, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn, critical: critical})
+}
+
+// Run executes every check concurrently and returns their results in registration order.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.Lock()
+	checks := append([]check(nil), c.checks...)
+	c.mu.Unlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, chk := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, chk)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, CheckedAt: time.Now().UTC().Format(time.RFC3339), Checks: results}
+	for _, r := range results {
+		switch {
+		case r.Status == StatusFail:
+			report.Status = StatusUnavailable
+		case r.Status == StatusWarn && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run executes one check with the timeout, turning panics into failures.
+func (c *Checker) run(ctx context.Context, chk check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result = Result{Name: chk.name, Critical: chk.critical, Status: StatusPass}
+	start := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			result.Status, result.Error = failStatus(chk.critical), redact.String(fmt.Sprintf("check panicked: %v", p))
+		}
+		result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	// The reports are served without authentication, so secrets in dependency errors
+	// (connection strings, upstream URLs) are redacted.
+	if err := chk.fn(ctx); err != nil {
+		result.Status, result.Error = failStatus(chk.critical), redact.String(err.Error())
+	}
+	return result
+}
+
+// failStatus is the status of a failed check.
+func failStatus(critical bool) string {
+	if critical {
+		return StatusFail
+	}
+	return StatusWarn
+}
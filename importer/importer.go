@@ -0,0 +1,226 @@
+// Package importer reads federal funds rate observations from local files, so data can
+// be loaded without network access: CSV files of date,value rows and JSON responses saved
+// from Alpha Vantage or FRED.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// File formats accepted by Read. FormatAuto picks one from the file extension and content.
+const (
+	FormatAuto         = "auto"
+	FormatCSV          = "csv"
+	FormatAlphaVantage = "alphavantage"
+	FormatFRED         = "fred"
+)
+
+// Formats lists the formats that can be selected explicitly.
+var Formats = []string{FormatCSV, FormatAlphaVantage, FormatFRED}
+
+// ErrNoObservations is returned for files without a single observation.
+var ErrNoObservations = errors.New("no observations found")
+
+// fredMissing is the value FRED uses for missing observations.
+const fredMissing = "."
+
+// ReadFile reads the observations in the file at path. Each observation's Source is set
+// to the format it was read from (e.g. "fred").
+func ReadFile(path, format string) ([]dto.Observation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Byte order mark written by spreadsheet tools.
+	if format == "" || format == FormatAuto {
+		format, err = Detect(path, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	observations, err := Read(bytes.NewReader(data), format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return observations, nil
+}
+
+// Detect guesses the format of a file: .csv files are CSV, and JSON documents are FRED
+// responses if they have an "observations" array and Alpha Vantage responses if they have
+// "data" (matched case-insensitively, as when decoding).
+func Detect(path string, data []byte) (string, error) {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV, nil
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", fmt.Errorf("%s: cannot detect format: not a .csv file or a JSON object", path)
+	}
+	switch {
+	case probe["observations"] != nil:
+		return FormatFRED, nil
+	case hasKey(probe, "data"):
+		return FormatAlphaVantage, nil
+	default:
+		return "", fmt.Errorf("%s: cannot detect format: JSON has neither \"observations\" (FRED) nor \"data\" (Alpha Vantage)", path)
+	}
+}
+
+// Read reads observations in the given format. Every row must have a valid date and
+// value, except FRED's "." for missing values, which are skipped. Dates are moved to the
+// first of their month, and a month may only have one observation.
+func Read(r io.Reader, format string) ([]dto.Observation, error) {
+	var observations []dto.Observation
+	var err error
+	switch format {
+	case FormatCSV:
+		observations, err = readCSV(r)
+	case FormatAlphaVantage:
+		observations, err = readAlphaVantage(r)
+	case FormatFRED:
+		observations, err = readFRED(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q, use %s", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(observations) == 0 {
+		return nil, ErrNoObservations
+	}
+	seen := make(map[time.Time]bool, len(observations))
+	for _, o := range observations {
+		if seen[o.Date] {
+			return nil, fmt.Errorf("more than one observation for %s; the rates are monthly", o.Date.Format("2006-01"))
+		}
+		seen[o.Date] = true
+	}
+	return observations, nil
+}
+
+// readCSV reads date,value rows. A header row is optional, and FRED's CSV downloads
+// (observation_date,FEDFUNDS) are accepted as they are.
+func readCSV(r io.Reader) ([]dto.Observation, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var observations []dto.Observation
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return observations, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && !startsWithDigit(record[0]) {
+			continue // Header row.
+		}
+		if record[1] == fredMissing {
+			continue
+		}
+		o, err := parseObservation(record[0], record[1], FormatCSV)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		observations = append(observations, o)
+	}
+}
+
+// readAlphaVantage reads a saved FEDERAL_FUNDS_RATE response.
+func readAlphaVantage(r io.Reader) ([]dto.Observation, error) {
+	var response dto.AlphaVantageResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid Alpha Vantage JSON: %v", err)
+	}
+	if len(response.Data) == 0 && (response.Note != "" || response.Information != "") {
+		return nil, fmt.Errorf("the saved response is a rate limit message, not data")
+	}
+	observations := make([]dto.Observation, 0, len(response.Data))
+	for i, record := range response.Data {
+		o, err := parseObservation(record["date"], record["value"], FormatAlphaVantage)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %v", i+1, err)
+		}
+		observations = append(observations, o)
+	}
+	return observations, nil
+}
+
+// fredResponse is the part of a FRED series/observations JSON response that is read.
+type fredResponse struct {
+	Observations []struct {
+		Date  string `json:"date"`
+		Value string `json:"value"`
+	} `json:"observations"`
+}
+
+// readFRED reads a saved FRED series/observations response (e.g. for FEDFUNDS).
+func readFRED(r io.Reader) ([]dto.Observation, error) {
+	var response fredResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid FRED JSON: %v", err)
+	}
+	observations := make([]dto.Observation, 0, len(response.Observations))
+	for i, record := range response.Observations {
+		if record.Value == fredMissing {
+			continue
+		}
+		o, err := parseObservation(record.Date, record.Value, FormatFRED)
+		if err != nil {
+			return nil, fmt.Errorf("observation %d: %v", i+1, err)
+		}
+		observations = append(observations, o)
+	}
+	return observations, nil
+}
+
+// parseObservation parses a YYYY-MM-DD or YYYY-MM date and a rate in percent. The date is
+// moved to the first of its month, the date monthly observations are stored under.
+func parseObservation(date, value, source string) (dto.Observation, error) {
+	date, value = strings.TrimSpace(date), strings.TrimSpace(value)
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		if t, err = time.Parse("2006-01", date); err != nil {
+			return dto.Observation{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or YYYY-MM", date)
+		}
+	}
+	// ParseFloat accepts NaN and Inf, which cannot be encoded as JSON.
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return dto.Observation{}, fmt.Errorf("invalid rate %q for %s", value, date)
+	}
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dto.Observation{Date: month, Value: rate, Source: source}, nil
+}
+
+// hasKey reports whether object has key, ignoring case.
+func hasKey(object map[string]json.RawMessage, key string) bool {
+	for k := range object {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func startsWithDigit(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
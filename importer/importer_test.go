@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		want    []dto.Observation
+		wantErr string // Substring of the expected error; empty for success.
+	}{
+		{
+			name:   "CSV without a header",
+			format: FormatCSV,
+			input:  "2024-01-01,5.33\n2024-02-01,5.33\n",
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatCSV}, {Date: month(2024, 2), Value: 5.33, Source: FormatCSV}},
+		},
+		{
+			name:   "CSV with a header",
+			format: FormatCSV,
+			input:  "date,value\n2024-01-01,5.33\n",
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatCSV}},
+		},
+		{
+			name:   "FRED CSV download with a missing value",
+			format: FormatCSV,
+			input:  "observation_date,FEDFUNDS\n2024-01-01,5.33\n2024-02-01,.\n2024-03-01,5.33\n",
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatCSV}, {Date: month(2024, 3), Value: 5.33, Source: FormatCSV}},
+		},
+		{
+			name:   "CSV with comments, spaces and year-month dates",
+			format: FormatCSV,
+			input:  "# Federal funds rate\n2024-01, 5.33\n",
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatCSV}},
+		},
+		{
+			name:   "dates are moved to the first of the month",
+			format: FormatCSV,
+			input:  "2024-01-31,5.33\n",
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatCSV}},
+		},
+		{
+			name:    "two observations in one month",
+			format:  FormatCSV,
+			input:   "2024-01-01,5.33\n2024-01-15,5.31\n",
+			wantErr: "more than one observation for 2024-01",
+		},
+		{
+			name:    "CSV header only",
+			format:  FormatCSV,
+			input:   "date,value\n",
+			wantErr: ErrNoObservations.Error(),
+		},
+		{
+			name:    "CSV invalid rate reports the line",
+			format:  FormatCSV,
+			input:   "date,value\n2024-01-01,5.33\n2024-02-01,n/a\n",
+			wantErr: `line 3: invalid rate "n/a"`,
+		},
+		{
+			name:    "CSV NaN rate",
+			format:  FormatCSV,
+			input:   "2024-01-01,NaN\n",
+			wantErr: `line 1: invalid rate "NaN"`,
+		},
+		{
+			name:    "CSV infinite rate",
+			format:  FormatCSV,
+			input:   "2024-01-01,-Inf\n",
+			wantErr: `line 1: invalid rate "-Inf"`,
+		},
+		{
+			name:    "CSV invalid date",
+			format:  FormatCSV,
+			input:   "2024-13-01,5.33\n",
+			wantErr: `invalid date "2024-13-01"`,
+		},
+		{
+			name:    "CSV with a third column",
+			format:  FormatCSV,
+			input:   "2024-01-01,5.33,x\n",
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:   "Alpha Vantage",
+			format: FormatAlphaVantage,
+			input:  `{"name": "Effective Federal Funds Rate", "data": [{"date": "2024-02-01", "value": "5.33"}, {"date": "2024-01-01", "value": "5.33"}]}`,
+			want:   []dto.Observation{{Date: month(2024, 2), Value: 5.33, Source: FormatAlphaVantage}, {Date: month(2024, 1), Value: 5.33, Source: FormatAlphaVantage}},
+		},
+		{
+			name:    "Alpha Vantage rate limit Note",
+			format:  FormatAlphaVantage,
+			input:   `{"Note": "Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`,
+			wantErr: "rate limit message",
+		},
+		{
+			name:    "Alpha Vantage Information",
+			format:  FormatAlphaVantage,
+			input:   `{"Information": "The **demo** API key is for demo purposes only."}`,
+			wantErr: "rate limit message",
+		},
+		{
+			name:    "Alpha Vantage invalid value",
+			format:  FormatAlphaVantage,
+			input:   `{"data": [{"date": "2024-01-01", "value": "."}]}`,
+			wantErr: `entry 1: invalid rate "."`,
+		},
+		{
+			name:    "FRED infinite rate",
+			format:  FormatFRED,
+			input:   `{"observations": [{"date": "2024-01-01", "value": "Inf"}]}`,
+			wantErr: `observation 1: invalid rate "Inf"`,
+		},
+		{
+			name:   "FRED with a missing value",
+			format: FormatFRED,
+			input:  `{"observations": [{"date": "2024-01-01", "value": "5.33"}, {"date": "2024-02-01", "value": "."}]}`,
+			want:   []dto.Observation{{Date: month(2024, 1), Value: 5.33, Source: FormatFRED}},
+		},
+		{
+			name:    "FRED with only missing values",
+			format:  FormatFRED,
+			input:   `{"observations": [{"date": "2024-01-01", "value": "."}]}`,
+			wantErr: ErrNoObservations.Error(),
+		},
+		{
+			name:    "FRED invalid JSON",
+			format:  FormatFRED,
+			input:   `{"observations": [`,
+			wantErr: "invalid FRED JSON",
+		},
+		{
+			name:    "unknown format",
+			format:  "xlsx",
+			input:   "",
+			wantErr: `unknown format "xlsx"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Read() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Date.Equal(tt.want[i].Date) || got[i].Value != tt.want[i].Value || got[i].Source != tt.want[i].Source {
+					t.Errorf("observation %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadNoObservationsIsSentinel(t *testing.T) {
+	_, err := Read(strings.NewReader(""), FormatCSV)
+	if !errors.Is(err, ErrNoObservations) {
+		t.Errorf("Read() error = %v, want ErrNoObservations", err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"rates.csv", "", FormatCSV, false},
+		{"RATES.CSV", "", FormatCSV, false},
+		{"fedfunds.json", `{"observations": []}`, FormatFRED, false},
+		{"av.json", `{"data": []}`, FormatAlphaVantage, false},
+		{"av-title-case.json", `{"Data": []}`, FormatAlphaVantage, false},
+		{"other.json", `{"rates": []}`, "", true},
+		{"rates.txt", "2024-01-01,5.33", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Detect(tt.path, []byte(tt.data))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Detect(%q) = %q, %v; want %q, error %v", tt.path, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
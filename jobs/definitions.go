@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+	"federal-funds-rate-metrics-ByYear/export"
+	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
+)
+
+// Job kinds registered by RegisterDefaults.
+const (
+	KindRefresh   = "refresh"   // Fetch from the upstream source and recompute insights.
+	KindRecompute = "recompute" // Recompute insights from stored observations.
+	KindExport    = "export"    // Write a snapshot archive of the observations and insights tables.
+)
+
+// RegisterDefaults registers the application's job kinds on q.
+func RegisterDefaults(q *Queue, client *source.Client, exportOptions export.Options) {
+	q.Register(KindRefresh, func(ctx context.Context) error {
+		data, err := client.FetchFederalFundsRate(ctx)
+		if err != nil {
+			appmetrics.RecordRefresh(false)
+			return fmt.Errorf("failed to fetch data: %v", err)
+		}
+		insights, err := services.RefreshInsights(ctx, data, source.Name)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Refreshed insights", "job", KindRefresh, "years", len(insights))
+		return nil
+	})
+
+	q.Register(KindRecompute, func(ctx context.Context) error {
+		insights, err := services.RecomputeInsights(ctx)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Recomputed insights", "job", KindRecompute, "years", len(insights))
+		return nil
+	})
+
+	q.Register(KindExport, func(ctx context.Context) error {
+		path, manifest, err := export.Snapshot(ctx, exportOptions)
+		if err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Exported snapshot", "job", KindExport, "path", path,
+			"observations", manifest.Tables[0].Rows, "years", manifest.Tables[1].Rows)
+		return nil
+	})
+}
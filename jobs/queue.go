@@ -0,0 +1,288 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+	"federal-funds-rate-metrics-ByYear/redact"
+	"federal-funds-rate-metrics-ByYear/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// historySize is how many finished jobs are kept for listing.
+const historySize = 100
+
+var (
+	// ErrQueueFull is returned by Submit when the queue is at capacity.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrUnknownKind is returned by Submit for a kind with no registered handler.
+	ErrUnknownKind = errors.New("unknown job kind")
+	// ErrStopped is returned by Submit after the queue has been stopped.
+	ErrStopped = errors.New("job queue is stopped")
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Func is the work performed by a job kind.
+type Func func(ctx context.Context) error
+
+// Job describes a submitted unit of work and its current status.
+type Job struct {
+	ID         int64      `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     Status     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Queue is a bounded in-process job queue processed by a fixed number of workers.
+type Queue struct {
+	mu       sync.Mutex
+	handlers map[string]Func
+	jobs     []*Job // Submission order; trimmed to historySize finished jobs.
+	pending  chan *Job
+	nextID   int64
+	workers  int
+	stopped  bool
+	wg       sync.WaitGroup
+
+	// schedules delivers interval changes to the running schedule of each kind.
+	schedules map[string]chan time.Duration
+}
+
+// NewQueue creates a queue holding up to capacity pending jobs, processed by workers goroutines.
+func NewQueue(workers, capacity int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Queue{
+		handlers:  make(map[string]Func),
+		pending:   make(chan *Job, capacity),
+		workers:   workers,
+		schedules: make(map[string]chan time.Duration),
+	}
+}
+
+// Register sets the handler for a job kind. It must be called before Start.
+func (q *Queue) Register(kind string, fn Func) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = fn
+}
+
+// Kinds returns the registered job kinds.
+func (q *Queue) Kinds() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// Start launches the workers. They exit once ctx is cancelled, after finishing their current job.
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-q.pending:
+					appmetrics.SetQueueLength(len(q.pending))
+					q.run(ctx, job)
+				}
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		q.mu.Lock()
+		q.stopped = true
+		q.mu.Unlock()
+	}()
+}
+
+// Wait blocks until all workers and schedules have exited.
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// Submit enqueues a job of the given kind and returns a snapshot of it.
+func (q *Queue) Submit(kind string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return Job{}, ErrStopped
+	}
+	if _, ok := q.handlers[kind]; !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	q.nextID++
+	job := &Job{ID: q.nextID, Kind: kind, Status: StatusQueued, CreatedAt: time.Now()}
+	select {
+	case q.pending <- job:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs = append(q.jobs, job)
+	q.trim()
+	appmetrics.SetQueueLength(len(q.pending))
+	return *job, nil
+}
+
+// Jobs returns snapshots of known jobs, newest first.
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	list := make([]Job, 0, len(q.jobs))
+	for i := len(q.jobs) - 1; i >= 0; i-- {
+		list = append(list, *q.jobs[i])
+	}
+	return list
+}
+
+// OldestRunning returns when the longest-running job was started, and false if no
+// worker is running a job.
+func (q *Queue) OldestRunning() (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var oldest time.Time
+	for _, job := range q.jobs {
+		if job.Status == StatusRunning && (oldest.IsZero() || job.StartedAt.Before(oldest)) {
+			oldest = *job.StartedAt
+		}
+	}
+	return oldest, !oldest.IsZero()
+}
+
+// Schedule submits a job of the given kind every interval until ctx is cancelled.
+// A non-positive interval disables the schedule. Calling Schedule again for the same
+// kind changes the interval of the running schedule instead of starting another one;
+// the next job is then submitted one new interval later.
+func (q *Queue) Schedule(ctx context.Context, kind string, interval time.Duration) {
+	q.mu.Lock()
+	updates, running := q.schedules[kind]
+	if !running {
+		updates = make(chan time.Duration, 1)
+		q.schedules[kind] = updates
+	}
+	q.mu.Unlock()
+
+	if running {
+		// Replace a change the schedule has not picked up yet so the latest interval wins.
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- interval
+		return
+	}
+
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		var ticker *time.Ticker
+		var tick <-chan time.Time // nil while the schedule is disabled
+		setInterval := func(d time.Duration) {
+			if ticker != nil {
+				ticker.Stop()
+				ticker, tick = nil, nil
+			}
+			if d > 0 {
+				ticker = time.NewTicker(d)
+				tick = ticker.C
+			}
+		}
+		setInterval(interval)
+		defer setInterval(0)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case d := <-updates:
+				setInterval(d)
+			case <-tick:
+				if _, err := q.Submit(kind); err != nil {
+					slog.ErrorContext(ctx, "Scheduled job not submitted", "kind", kind, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// run executes a job and records its outcome.
+func (q *Queue) run(ctx context.Context, job *Job) {
+	q.mu.Lock()
+	fn := q.handlers[job.Kind]
+	started := time.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &started
+	q.mu.Unlock()
+
+	spanCtx, span := tracing.Tracer().Start(ctx, "job "+job.Kind, trace.WithAttributes(attribute.Int64("job.id", job.ID)))
+	err := fn(spanCtx)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	q.mu.Lock()
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = redact.String(err.Error())
+		slog.ErrorContext(ctx, "Job failed", "job", job.ID, "kind", job.Kind, "duration", finished.Sub(started), "error", err)
+	} else {
+		job.Status = StatusSucceeded
+	}
+	q.trim()
+	q.mu.Unlock()
+
+	appmetrics.RecordJob(job.Kind, string(job.Status), finished.Sub(started))
+}
+
+// trim drops the oldest finished jobs beyond historySize. Callers must hold q.mu.
+func (q *Queue) trim() {
+	excess := len(q.jobs) - historySize
+	if excess <= 0 {
+		return
+	}
+	kept := q.jobs[:0]
+	for _, job := range q.jobs {
+		if excess > 0 && (job.Status == StatusSucceeded || job.Status == StatusFailed) {
+			excess--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	q.jobs = kept
+}
@@ -0,0 +1,140 @@
+// Package logging configures structured logging with log/slog and carries
+// request IDs through contexts so every log line of a request can be correlated.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"federal-funds-rate-metrics-ByYear/redact"
+	"federal-funds-rate-metrics-ByYear/tracing"
+)
+
+// Output formats accepted by Setup.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// level is the minimum level of the logger installed by Setup. It can be changed
+// at runtime with SetLevel.
+var level = new(slog.LevelVar)
+
+// Setup installs the default slog logger writing to stderr in the given format
+// ("text" or "json") at the given level ("debug", "info", "warn" or "error").
+// Output from the standard log package is routed through the same logger.
+func Setup(format, lvl string) error {
+	if err := SetLevel(lvl); err != nil {
+		return err
+	}
+	handler, err := newHandler(os.Stderr, format, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the minimum level of the logger installed by Setup.
+func SetLevel(lvl string) error {
+	parsed, err := ParseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// New creates a logger that adds the request and trace IDs found in the context of each record.
+func New(w io.Writer, format, lvl string) (*slog.Logger, error) {
+	parsed, err := ParseLevel(lvl)
+	if err != nil {
+		return nil, err
+	}
+	handler, err := newHandler(w, format, parsed)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(handler), nil
+}
+
+// newHandler creates the redacting, context-aware handler for the given format.
+func newHandler(w io.Writer, format string, lvl slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format must be text or json, got %q", format)
+	}
+	return contextHandler{handler}, nil
+}
+
+// redactAttr removes secrets from the message and from string and error attributes.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redact.String(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(redact.String(err.Error()))
+		}
+	}
+	return a
+}
+
+// ParseLevel parses a level name; an empty name means info.
+func ParseLevel(level string) (slog.Level, error) {
+	var lvl slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("log level must be debug, info, warn or error, got %q", level)
+	}
+	return lvl, nil
+}
+
+// contextHandler adds request_id and trace_id attributes from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -1,47 +1,139 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
 
 	"federal-funds-rate-metrics-ByYear/config"
-	"federal-funds-rate-metrics-ByYear/db"
 	"federal-funds-rate-metrics-ByYear/handle"
 	"federal-funds-rate-metrics-ByYear/metrics"
 )
 
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	args    string // Argument synopsis shown in the usage.
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the subcommands in the order they are shown in the usage.
+var commands = []command{
+	{"serve", "[flags]", "Run the HTTP server (the default when no command is given)", serve},
+	{"fetch", "[flags]", "Fetch observations from the configured source once and recompute insights", fetch},
+	{"import", "[--format auto|csv|alphavantage|fred] [--dry-run] [flags] FILE...", "Load observations from local files and recompute insights", importFiles},
+	{"migrate", "[flags]", "Apply pending database migrations", migrate},
+	{"export", "[--data observations|insights] [--format csv|json] [--from DATE] [--to DATE] [--output FILE] [flags]", "Write stored data as CSV or JSON", exportData},
+	{"snapshot", "[flags]", "Write a compressed snapshot archive of all stored data to the export directory", snapshot},
+	{"users", "create --name NAME --email EMAIL | list | delete [flags] EMAIL", "Manage users", users},
+	{"config", "validate [flags]", "Report every configuration problem at once", configCommand},
+	{"dashboard", "", "Print the Grafana dashboard for the registered metrics", dashboard},
+}
+
+// exitCode is returned by commands that have already reported their failure
+// and only need the process to exit with a specific status.
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
 func main() {
-	port := ":8085"
-	// Load the configuration from the .env file
-	appConfig, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Error loading configuration: %v", err)
+	args := os.Args[1:]
+	// Without a command, or with only flags, serve as before subcommands existed.
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
 	}
 
-	// Pass the configuration to packages that need it
-	handle.InitConfig(appConfig)
-
-	// Connect to the database
-	db.Connect(appConfig.DatabaseURL)
-	defer db.Close()
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
 
-	// Register metrics with Prometheus
-	metrics.RegisterMetrics()
+	// Commands return instead of exiting so their deferred cleanup (DB pool, tracing) always happens.
+	err := cmd.run(args)
+	var code exitCode
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.As(err, &code):
+		os.Exit(int(code))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	// Start background routines to update metrics.
-	metrics.StartUptime()
-	metrics.UpdateSystemMetrics()
+// usage prints the list of commands.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+		fmt.Fprintf(os.Stderr, "  %-10s   %s\n", "", strings.TrimSpace(c.name+" "+c.args))
+	}
+	fmt.Fprintf(os.Stderr, "\nEvery command accepts the configuration flags; run \"%s serve --help\" to list them.\n", os.Args[0])
+}
 
-	// Instrument and register HTTP handlers with static route patterns.
-	// These static patterns ensure dynamic parts (e.g., email or id) are not included in the metric labels.
-	http.Handle("/", metrics.InstrumentHandler("/", http.HandlerFunc(handle.FederalFundsHandlerInsight)))
-	http.Handle("/auth/{email}", metrics.InstrumentHandler("/auth/{email}", http.HandlerFunc(handle.UserInfo)))
-	http.Handle("/create", metrics.InstrumentHandler("/create", http.HandlerFunc(handle.CreateUser)))
-	http.Handle("/health", metrics.InstrumentHandler("/health", http.HandlerFunc(handle.HealthCheck)))
+// dashboard prints the Grafana dashboard for the registered metrics.
+func dashboard(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("dashboard takes no arguments")
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(metrics.GrafanaDashboard(handle.DashboardTitle)); err != nil {
+		return fmt.Errorf("writing dashboard: %v", err)
+	}
+	return nil
+}
 
-	// Expose the /metrics endpoint for Prometheus to scrape real-time metrics.
-	http.Handle("/metrics", metrics.MetricsHandler())
+// configCommand runs "config validate".
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return fmt.Errorf("usage: config validate [flags]")
+	}
+	if code := validateConfig(args[1:]); code != 0 {
+		return exitCode(code)
+	}
+	return nil
+}
 
-	log.Printf("Server is running on port %s...", port)
-	log.Fatal(http.ListenAndServe(port, nil))
+// validateConfig loads the configuration with the given flags and prints the result.
+// It returns the process exit code.
+func validateConfig(args []string) int {
+	appConfig, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		fmt.Fprintf(os.Stderr, "Configuration has %d problem(s):\n", len(invalid.Problems))
+		for _, problem := range invalid.Problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	source := appConfig.File
+	if source == "" {
+		source = "defaults and environment"
+	}
+	fmt.Printf("Configuration is valid (%s)\n", source)
+	return 0
 }
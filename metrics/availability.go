@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// heartbeatInterval is how often the current run is persisted to the state file.
+// A crash loses at most one interval of uptime.
+const heartbeatInterval = 15 * time.Second
+
+// availabilityRetention is how much history is kept in the state file.
+const availabilityRetention = 30 * 24 * time.Hour
+
+// availabilityWindows are the rolling windows exported by AvailabilityWindow.
+var availabilityWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"30d", availabilityRetention},
+}
+
+// period is a closed time interval.
+type period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// run is a single process lifetime, from start to its last persisted heartbeat.
+type run struct {
+	Start         time.Time `json:"start"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	CleanStop     bool      `json:"clean_stop"` // Stopped by a shutdown signal rather than crashing.
+}
+
+// availabilityState is what gets persisted between runs.
+type availabilityState struct {
+	Runs    []run    `json:"runs"`
+	Outages []period `json:"outages"` // Periods where health probes failed while running.
+}
+
+// availabilityTracker records runs and failed probes and computes availability from them.
+type availabilityTracker struct {
+	mu        sync.Mutex
+	path      string
+	state     availabilityState
+	downSince time.Time // Start of the current failed-probe outage, zero if healthy.
+}
+
+// StartUptime begins a background routine that updates the uptime, downtime and availability gauges.
+// Heartbeats are persisted to stateFile so downtime between runs is counted. The final
+// heartbeat is written when ctx is cancelled and marks the run as cleanly stopped; the gap
+// after a clean stop (a deploy or planned restart) is not counted as downtime, only the
+// gap after a crash is.
+func (m *Metrics) StartUptime(ctx context.Context, stateFile string) {
+	tracker := m.availability
+	startTime := time.Now()
+	tracker.start(stateFile, startTime)
+
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		lastHeartbeat := startTime
+		for {
+			select {
+			case <-ctx.Done():
+				tracker.heartbeat(time.Now(), true)
+				return
+			case now := <-ticker.C:
+				m.Uptime.Set(now.Sub(startTime).Seconds())
+				if now.Sub(lastHeartbeat) >= heartbeatInterval {
+					tracker.heartbeat(now, false)
+					lastHeartbeat = now
+				}
+				tracker.export(m, now)
+			}
+		}
+	}()
+}
+
+// RecordHealthProbe records the outcome of a health probe.
+// Time between the first failed probe and the next successful one counts as downtime.
+func (m *Metrics) RecordHealthProbe(healthy bool) {
+	m.availability.probe(time.Now(), healthy)
+}
+
+// start loads previous state from path and begins a new run.
+func (t *availabilityTracker) start(path string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.path = path
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &t.state); err != nil {
+			slog.Warn("Ignoring unreadable availability state", "path", path, "error", err)
+			t.state = availabilityState{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Error("Error reading availability state", "path", path, "error", err)
+	}
+
+	t.state.Runs = append(t.state.Runs, run{Start: now, LastHeartbeat: now})
+	t.prune(now)
+	t.save()
+}
+
+// heartbeat extends the current run to now and persists the state.
+func (t *availabilityTracker) heartbeat(now time.Time, clean bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.state.Runs) == 0 {
+		return
+	}
+	current := &t.state.Runs[len(t.state.Runs)-1]
+	current.LastHeartbeat = now
+	current.CleanStop = clean
+	if clean && !t.downSince.IsZero() {
+		t.state.Outages = append(t.state.Outages, period{Start: t.downSince, End: now})
+		t.downSince = time.Time{}
+	}
+	t.prune(now)
+	t.save()
+}
+
+// probe records a health probe outcome.
+func (t *availabilityTracker) probe(now time.Time, healthy bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case !healthy && t.downSince.IsZero():
+		t.downSince = now
+	case healthy && !t.downSince.IsZero():
+		t.state.Outages = append(t.state.Outages, period{Start: t.downSince, End: now})
+		t.downSince = time.Time{}
+	}
+}
+
+// export updates the downtime and availability gauges of m as of now.
+func (t *availabilityTracker) export(m *Metrics, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, w := range availabilityWindows {
+		observed, down := t.window(now, w.duration)
+		m.AvailabilityWindow.WithLabelValues(w.label).Set(availability(observed, down))
+		if w.duration == availabilityRetention {
+			m.Downtime.Set(down.Seconds())
+			m.AvailabilityRate.Set(availability(observed, down))
+		}
+	}
+}
+
+// window returns how much of the last d has been observed (since the first recorded run,
+// excluding gaps after clean stops) and how much of that was downtime.
+func (t *availabilityTracker) window(now time.Time, d time.Duration) (time.Duration, time.Duration) {
+	if len(t.state.Runs) == 0 {
+		return 0, 0
+	}
+	from := now.Add(-d)
+	if first := t.state.Runs[0].Start; first.After(from) {
+		from = first
+	}
+
+	var down, planned time.Duration
+	// Gaps between the last heartbeat of one run and the start of the next. A gap after a
+	// clean stop is planned and left out of the window altogether.
+	for i := 1; i < len(t.state.Runs); i++ {
+		gap := overlap(t.state.Runs[i-1].LastHeartbeat, t.state.Runs[i].Start, from, now)
+		if t.state.Runs[i-1].CleanStop {
+			planned += gap
+		} else {
+			down += gap
+		}
+	}
+	// Failed probes while running, including one still in progress.
+	for _, o := range t.state.Outages {
+		down += overlap(o.Start, o.End, from, now)
+	}
+	if !t.downSince.IsZero() {
+		down += overlap(t.downSince, now, from, now)
+	}
+	return now.Sub(from) - planned, down
+}
+
+// prune drops history older than the retention period.
+func (t *availabilityTracker) prune(now time.Time) {
+	cutoff := now.Add(-availabilityRetention)
+	runs := t.state.Runs[:0]
+	for i, r := range t.state.Runs {
+		// Keep the run before the first retained one so the gap between them is still counted.
+		if r.LastHeartbeat.After(cutoff) || (i+1 < len(t.state.Runs) && t.state.Runs[i+1].Start.After(cutoff)) {
+			runs = append(runs, r)
+		}
+	}
+	t.state.Runs = runs
+
+	outages := t.state.Outages[:0]
+	for _, o := range t.state.Outages {
+		if o.End.After(cutoff) {
+			outages = append(outages, o)
+		}
+	}
+	t.state.Outages = outages
+}
+
+// save writes the state file atomically. Failures are logged, not fatal.
+func (t *availabilityTracker) save() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.Marshal(t.state)
+	if err != nil {
+		slog.Error("Error encoding availability state", "path", t.path, "error", err)
+		return
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		slog.Error("Error writing availability state", "path", t.path, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		slog.Error("Error writing availability state", "path", t.path, "error", err)
+	}
+}
+
+// overlap returns the length of [start, end] that falls inside [from, to].
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// availability converts observed time and downtime into a percentage.
+func availability(observed, down time.Duration) float64 {
+	if observed <= 0 {
+		return 100.0
+	}
+	return float64(observed-down) / float64(observed) * 100.0
+}
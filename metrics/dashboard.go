@@ -0,0 +1,352 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metricType is the Prometheus type of a defined metric.
+type metricType string
+
+const (
+	typeGauge     metricType = "gauge"
+	typeCounter   metricType = "counter"
+	typeHistogram metricType = "histogram"
+)
+
+// definition describes a metric as it was created, so dashboards are generated from the
+// same definitions as the collectors and cannot drift from them.
+type definition struct {
+	name   string // Fully qualified name, including namespace and subsystem.
+	base   string // Name as passed to the helper, used to group panels.
+	help   string
+	kind   metricType
+	labels []string
+}
+
+// dashboardSection is a dashboard row holding the metrics whose names start with one of its prefixes.
+type dashboardSection struct {
+	title    string
+	prefixes []string
+}
+
+// coreSections are the rows of the metrics created by New, in display order.
+// Applications add theirs with AddDashboardSection.
+var coreSections = []dashboardSection{
+	{"HTTP", []string{"http_"}},
+	{"Service level objectives", []string{"slo_"}},
+	{"Availability", []string{"application_"}},
+	{"System", []string{"cpu_", "memory_", "goroutines_", "open_file_", "gc_"}},
+}
+
+// dashboardQuantiles are the percentiles plotted for every histogram.
+var dashboardQuantiles = []float64{0.5, 0.9, 0.99}
+
+// Grafana panel geometry: two panels per line on the 24-column grid.
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// Dashboard is a Grafana dashboard JSON model. Only the fields needed for import are set.
+type Dashboard struct {
+	UID           string         `json:"uid"`
+	Title         string         `json:"title"`
+	Tags          []string       `json:"tags"`
+	Timezone      string         `json:"timezone"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Refresh       string         `json:"refresh"`
+	Time          DashboardRange `json:"time"`
+	Templating    Templating     `json:"templating"`
+	Panels        []Panel        `json:"panels"`
+}
+
+// DashboardRange is the default time range of a dashboard.
+type DashboardRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Templating holds dashboard variables.
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard variable. Only the datasource variable is generated.
+type Variable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+// Panel is a dashboard panel or a row header.
+type Panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	GridPos     GridPos      `json:"gridPos"`
+	Datasource  *Datasource  `json:"datasource,omitempty"`
+	Targets     []Target     `json:"targets,omitempty"`
+	FieldConfig *FieldConfig `json:"fieldConfig,omitempty"`
+	Collapsed   bool         `json:"collapsed,omitempty"`
+}
+
+// GridPos places a panel on the dashboard grid.
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Datasource references the Prometheus datasource chosen through the datasource variable.
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Target is a PromQL query of a panel.
+type Target struct {
+	RefID        string      `json:"refId"`
+	Expr         string      `json:"expr"`
+	LegendFormat string      `json:"legendFormat,omitempty"`
+	Datasource   *Datasource `json:"datasource,omitempty"`
+}
+
+// FieldConfig sets the display unit of a panel.
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+// FieldDefaults holds the field options applied to every series of a panel.
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+// GrafanaDashboard builds a Grafana dashboard with a row per metric group and a panel per metric:
+// percentiles for histograms, per-second rates for counters (plus an error ratio where an
+// errors counter has a matching requests counter) and current values for gauges.
+func (m *Metrics) GrafanaDashboard(title string) Dashboard {
+	d := Dashboard{
+		UID:           dashboardUID(title),
+		Title:         title,
+		Tags:          []string{"generated"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Refresh:       "30s",
+		Time:          DashboardRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []Variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+		}},
+	}
+
+	m.mu.Lock()
+	definitions := append([]definition(nil), m.opts.definitions...)
+	sections := append([]dashboardSection(nil), m.sections...)
+	m.mu.Unlock()
+
+	grouped := make(map[string][]definition)
+	for _, def := range definitions {
+		section := sectionOf(sections, def.base)
+		grouped[section] = append(grouped[section], def)
+	}
+
+	layout := &dashboardLayout{}
+	titles := make([]string, 0, len(sections)+1)
+	for _, s := range sections {
+		titles = append(titles, s.title)
+	}
+	titles = append(titles, "Other")
+
+	for _, section := range titles {
+		defs := grouped[section]
+		if len(defs) == 0 {
+			continue
+		}
+		d.Panels = append(d.Panels, layout.row(section))
+		for _, def := range defs {
+			d.Panels = append(d.Panels, layout.panel(def.help, def.name, unitOf(def), queries(def)))
+			if requests, ok := requestsFor(definitions, def); ok {
+				d.Panels = append(d.Panels, layout.panel("Error ratio ("+strings.TrimSuffix(def.base, "_errors_total")+")", def.name+" / "+requests.name, "percentunit", errorRatioQueries(def, requests)))
+			}
+		}
+	}
+	return d
+}
+
+// queries returns the PromQL targets plotted for a metric.
+func queries(def definition) []Target {
+	legend := legendOf(def.labels)
+	switch def.kind {
+	case typeHistogram:
+		group := "le"
+		if len(def.labels) > 0 {
+			group = "le, " + def.labels[0]
+			legend = "{{" + def.labels[0] + "}}"
+		}
+		targets := make([]Target, 0, len(dashboardQuantiles))
+		for _, q := range dashboardQuantiles {
+			targets = append(targets, Target{
+				Expr:         fmt.Sprintf("histogram_quantile(%g, sum by (%s) (rate(%s_bucket[$__rate_interval])))", q, group, def.name),
+				LegendFormat: strings.TrimSpace(fmt.Sprintf("p%g %s", q*100, legend)),
+			})
+		}
+		return targets
+	case typeCounter:
+		return []Target{{Expr: fmt.Sprintf("sum%s (rate(%s[$__rate_interval]))", byClause(def.labels), def.name), LegendFormat: legend}}
+	default:
+		expr := def.name
+		if strings.HasSuffix(def.name, "_timestamp_seconds") {
+			// Grafana date units expect milliseconds.
+			expr += " * 1000"
+		}
+		return []Target{{Expr: expr, LegendFormat: legend}}
+	}
+}
+
+// requestsFor returns the requests counter matching an errors counter
+// (e.g. http_requests_total for http_errors_total), if there is one.
+func requestsFor(definitions []definition, def definition) (definition, bool) {
+	if def.kind != typeCounter || !strings.HasSuffix(def.name, "_errors_total") {
+		return definition{}, false
+	}
+	name := strings.TrimSuffix(def.name, "_errors_total") + "_requests_total"
+	for _, other := range definitions {
+		if other.name == name && other.kind == typeCounter {
+			return other, true
+		}
+	}
+	return definition{}, false
+}
+
+// errorRatioQueries divides the error rate by the request rate over their shared labels.
+func errorRatioQueries(errs, requests definition) []Target {
+	var shared []string
+	for _, l := range errs.labels {
+		for _, r := range requests.labels {
+			if l == r {
+				shared = append(shared, l)
+			}
+		}
+	}
+	by := byClause(shared)
+	return []Target{{
+		Expr:         fmt.Sprintf("sum%s (rate(%s[$__rate_interval])) / sum%s (rate(%s[$__rate_interval]))", by, errs.name, by, requests.name),
+		LegendFormat: legendOf(shared),
+	}}
+}
+
+// dashboardLayout assigns panel IDs and grid positions in order.
+type dashboardLayout struct {
+	nextID int
+	x, y   int
+}
+
+// row starts a new row header on its own line.
+func (l *dashboardLayout) row(title string) Panel {
+	if l.x > 0 {
+		l.x = 0
+		l.y += panelHeight
+	}
+	l.nextID++
+	p := Panel{ID: l.nextID, Type: "row", Title: title, GridPos: GridPos{X: 0, Y: l.y, W: 24, H: 1}}
+	l.y++
+	return p
+}
+
+// panel places a time series panel next to the previous one, wrapping after two.
+func (l *dashboardLayout) panel(title, description, unit string, targets []Target) Panel {
+	ds := &Datasource{Type: "prometheus", UID: "${datasource}"}
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+		targets[i].Datasource = ds
+	}
+	l.nextID++
+	p := Panel{
+		ID:          l.nextID,
+		Type:        "timeseries",
+		Title:       title,
+		Description: description,
+		GridPos:     GridPos{X: l.x, Y: l.y, W: panelWidth, H: panelHeight},
+		Datasource:  ds,
+		Targets:     targets,
+		FieldConfig: &FieldConfig{Defaults: FieldDefaults{Unit: unit}},
+	}
+	l.x += panelWidth
+	if l.x >= 24 {
+		l.x = 0
+		l.y += panelHeight
+	}
+	return p
+}
+
+// sectionOf returns the dashboard row a metric belongs to.
+func sectionOf(sections []dashboardSection, base string) string {
+	for _, s := range sections {
+		for _, prefix := range s.prefixes {
+			if strings.HasPrefix(base, prefix) {
+				return s.title
+			}
+		}
+	}
+	return "Other"
+}
+
+// unitOf picks a Grafana unit from the metric name suffix.
+func unitOf(def definition) string {
+	switch {
+	case strings.HasSuffix(def.base, "_timestamp_seconds"):
+		return "dateTimeFromNow"
+	case def.kind == typeCounter && strings.HasSuffix(def.base, "_bytes_total"):
+		return "Bps"
+	case def.kind == typeCounter:
+		return "ops"
+	case strings.HasSuffix(def.base, "_seconds"):
+		return "s"
+	case strings.HasSuffix(def.base, "_bytes"):
+		return "bytes"
+	case strings.HasSuffix(def.base, "_percent"), strings.HasSuffix(def.base, "_rate"):
+		return "percent"
+	case strings.HasSuffix(def.base, "_ratio"):
+		return "percentunit"
+	default:
+		return "short"
+	}
+}
+
+// byClause renders a PromQL "by" clause, or nothing for no labels.
+func byClause(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return " by (" + strings.Join(labels, ", ") + ")"
+}
+
+// legendOf renders a legend template showing every label.
+func legendOf(labels []string) string {
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, "{{"+l+"}}")
+	}
+	return strings.Join(parts, " ")
+}
+
+// dashboardUID derives a stable UID from the title, within Grafana's 40 character limit.
+func dashboardUID(title string) string {
+	uid := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, title)
+	if len(uid) > 40 {
+		uid = uid[:40]
+	}
+	return uid
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// defaultMetrics backs the package-level functions, which keep the original
+// single-application API working on top of a Metrics instance.
+var defaultMetrics = New()
+
+// Default returns the Metrics instance used by the package-level functions.
+func Default() *Metrics {
+	return defaultMetrics
+}
+
+// RegisterMetrics registers all the defined metrics with the default registry.
+// It is safe to call more than once and panics only if registration fails.
+func RegisterMetrics() {
+	if err := defaultMetrics.Register(); err != nil {
+		panic(err)
+	}
+}
+
+// MetricsHandler returns an HTTP handler for exposing metrics from the default registry.
+func MetricsHandler() http.Handler {
+	return defaultMetrics.Handler()
+}
+
+// InstrumentHandler wraps an http.Handler with HTTP metrics instrumentation using the provided route pattern.
+func InstrumentHandler(pattern string, handler http.Handler) http.Handler {
+	return defaultMetrics.InstrumentHandler(pattern, handler)
+}
+
+// HTTPMetricsMiddlewareWithPattern returns a middleware that records metrics using a user-supplied route pattern.
+func HTTPMetricsMiddlewareWithPattern(pattern string) func(http.Handler) http.Handler {
+	return defaultMetrics.HTTPMetricsMiddlewareWithPattern(pattern)
+}
+
+// RecordHTTPMetrics records HTTP metrics for a finished request on the default instance.
+func RecordHTTPMetrics(ctx context.Context, path, method string, start time.Time, isError bool) {
+	defaultMetrics.RecordHTTPMetrics(ctx, path, method, start, isError)
+}
+
+// StartUptime starts availability tracking on the default instance.
+func StartUptime(ctx context.Context, stateFile string) {
+	defaultMetrics.StartUptime(ctx, stateFile)
+}
+
+// RecordHealthProbe records a health probe outcome on the default instance.
+func RecordHealthProbe(healthy bool) {
+	defaultMetrics.RecordHealthProbe(healthy)
+}
+
+// UpdateSystemMetrics starts sampling process metrics on the default instance.
+func UpdateSystemMetrics(ctx context.Context, interval time.Duration) {
+	defaultMetrics.UpdateSystemMetrics(ctx, interval)
+}
+
+// StartSLOs starts tracking objectives on the default instance.
+func StartSLOs(ctx context.Context, slos []SLO, interval time.Duration) {
+	defaultMetrics.StartSLOs(ctx, slos, interval)
+}
+
+// SLOStatuses returns the current state of the objectives tracked by the default instance.
+func SLOStatuses() []SLOStatus {
+	return defaultMetrics.SLOStatuses()
+}
+
+// Wait blocks until the background routines of the default instance have exited.
+func Wait() {
+	defaultMetrics.Wait()
+}
+
+// GrafanaDashboard builds a Grafana dashboard for the metrics of the default instance.
+func GrafanaDashboard(title string) Dashboard {
+	return defaultMetrics.GrafanaDashboard(title)
+}
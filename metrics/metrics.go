@@ -1,225 +1,443 @@
 package metrics
 
 import (
+	"context"
 	"net/http"
-	"runtime"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"federal-funds-rate-metrics-ByYear/tracing"
 )
 
-// Create a custom registry to avoid default collectors.
-var customRegistry = prometheus.NewRegistry()
+// -----------------------
+// Options
+// -----------------------
+
+// options collects the settings applied by Option values.
+type options struct {
+	namespace   string
+	subsystem   string
+	constLabels prometheus.Labels
+	buckets     []float64
+	registerer  prometheus.Registerer
+	gatherer    prometheus.Gatherer
+	definitions []definition // Every metric created through the helpers, in creation order.
+}
+
+// Option configures a Metrics instance created by New.
+type Option func(*options)
+
+// WithNamespace prefixes every metric name with namespace (e.g. "funds" gives "funds_http_requests_total").
+func WithNamespace(namespace string) Option {
+	return func(o *options) { o.namespace = namespace }
+}
+
+// WithSubsystem adds subsystem between the namespace and the metric name.
+func WithSubsystem(subsystem string) Option {
+	return func(o *options) { o.subsystem = subsystem }
+}
+
+// WithConstLabels adds fixed labels (e.g. service or environment) to every metric.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(o *options) { o.constLabels = labels }
+}
+
+// WithBuckets sets the buckets of the HTTP duration histogram, which are also the default
+// for histograms added with NewHistogramVec.
+func WithBuckets(buckets []float64) Option {
+	return func(o *options) { o.buckets = buckets }
+}
+
+// WithRegistry registers and exposes metrics on reg. Tests can pass prometheus.NewRegistry()
+// to get an isolated set of metrics.
+func WithRegistry(reg *prometheus.Registry) Option {
+	return func(o *options) {
+		o.registerer = reg
+		o.gatherer = reg
+	}
+}
+
+// WithRegisterer registers metrics on r and serves them from g, for applications that
+// already own a registry (e.g. prometheus.DefaultRegisterer and prometheus.DefaultGatherer).
+func WithRegisterer(r prometheus.Registerer, g prometheus.Gatherer) Option {
+	return func(o *options) {
+		o.registerer = r
+		o.gatherer = g
+	}
+}
 
 // -----------------------
 // Metric Definitions
 // -----------------------
 
-// HTTPDuration is a histogram that tracks the response time of HTTP requests.
-var (
-	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Name:    "http_response_duration_seconds",
-		Help:    "Histogram of response time for HTTP requests",
-		Buckets: prometheus.DefBuckets,
-	}, []string{"path", "method"})
-)
+// sizeBuckets covers request and response bodies from 100B to 100MB.
+var sizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
 
-// HTTPRequestCount counts the total number of HTTP requests.
-var (
-	HTTPRequestCount = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "http_requests_total",
-		Help: "Total number of HTTP requests",
-	}, []string{"path", "method"})
-
-	// HTTPErrorCount counts the total number of HTTP errors.
-	HTTPErrorCount = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "http_errors_total",
-		Help: "Total number of HTTP errors",
-	}, []string{"path", "method"})
-)
+// Metrics holds the collectors every HTTP service needs: HTTP server metrics, SLOs,
+// availability and process resources, bound to one registerer. Applications add their
+// own metrics with NewGauge, NewCounterVec and the other New methods.
+// Create it with New; the zero value is not usable.
+type Metrics struct {
+	registerer   prometheus.Registerer
+	gatherer     prometheus.Gatherer
+	registerOnce sync.Once
+	registerErr  error
+	availability *availabilityTracker
+	httpBuckets  []float64
+	sloMu        sync.Mutex
+	slo          *sloTracker
+	gcCycles     atomic.Uint64  // Completed GC cycles at the last runtime sample.
+	gcPauseNanos atomic.Uint64  // Total GC pause time at the last runtime sample.
+	background   sync.WaitGroup // Background routines started by StartUptime, UpdateSystemMetrics and StartSLOs.
 
-// HTTPStatusCounter counts the total number of responses for each HTTP status code.
-var (
-	HTTPStatusCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name: "http_status_total",
-		Help: "Total number of HTTP responses by status code",
-	}, []string{"path", "method", "code"})
-)
+	// mu guards what applications add after New: their collectors, the metric
+	// definitions the dashboard is generated from and its sections.
+	mu         sync.Mutex
+	opts       *options
+	registered bool
+	added      []prometheus.Collector
+	sections   []dashboardSection
 
-// Uptime and downtime gauges.
-var (
-	Uptime = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "application_uptime_seconds",
-		Help: "Application uptime in seconds",
-	})
+	// HTTP server metrics, labeled by route pattern and method.
+	HTTPDuration      *prometheus.HistogramVec
+	HTTPRequestCount  *prometheus.CounterVec
+	HTTPErrorCount    *prometheus.CounterVec
+	HTTPStatusCounter *prometheus.CounterVec
+	HTTPRequestSize   *prometheus.HistogramVec
+	HTTPResponseSize  *prometheus.HistogramVec
+	HTTPInFlight      *prometheus.GaugeVec
 
-	Downtime = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "application_downtime_seconds",
-		Help: "Application downtime in seconds",
-	})
-)
+	// SLO gauges, labeled by route pattern and SLI kind.
+	SLOErrorBudgetRemaining *prometheus.GaugeVec
+	SLOBurnRate             *prometheus.GaugeVec
 
-// AvailabilityRate gauge computed as uptime / (uptime+downtime)*100.
-var AvailabilityRate = prometheus.NewGauge(prometheus.GaugeOpts{
-	Name: "application_availability_rate",
-	Help: "Application availability rate in percentage",
-})
-
-// CPU and Memory usage gauges.
-var (
-	CPUUsage = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "cpu_usage_percent",
-		Help: "CPU usage percentage",
+	// Uptime, downtime and availability gauges.
+	Uptime             prometheus.Gauge
+	Downtime           prometheus.Gauge
+	AvailabilityRate   prometheus.Gauge
+	AvailabilityWindow *prometheus.GaugeVec
+
+	// Process resource metrics.
+	CPUUsage     prometheus.Gauge
+	MemoryUsage  prometheus.Gauge
+	Goroutines   prometheus.Gauge
+	HeapAlloc    prometheus.Gauge
+	OpenFDs      prometheus.Gauge
+	GCCycles     prometheus.CounterFunc // Reads the totals last sampled by UpdateSystemMetrics.
+	GCPauseTotal prometheus.CounterFunc
+	GCLastPause  prometheus.Gauge
+}
+
+// New creates a Metrics instance. Without options it uses a fresh registry with no
+// default Go collectors, unprefixed metric names and prometheus.DefBuckets.
+// Call Register before exposing it.
+func New(opts ...Option) *Metrics {
+	o := &options{buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.registerer == nil {
+		reg := prometheus.NewRegistry()
+		o.registerer, o.gatherer = reg, reg
+	}
+
+	m := &Metrics{
+		registerer:   o.registerer,
+		gatherer:     o.gatherer,
+		availability: &availabilityTracker{},
+		httpBuckets:  o.buckets,
+		opts:         o,
+		sections:     append([]dashboardSection(nil), coreSections...),
+
+		HTTPDuration:      o.histogramVec("http_response_duration_seconds", "Histogram of response time for HTTP requests", o.buckets, "path", "method"),
+		HTTPRequestCount:  o.counterVec("http_requests_total", "Total number of HTTP requests", "path", "method"),
+		HTTPErrorCount:    o.counterVec("http_errors_total", "Total number of HTTP errors", "path", "method"),
+		HTTPStatusCounter: o.counterVec("http_status_total", "Total number of HTTP responses by status code", "path", "method", "code"),
+		HTTPRequestSize:   o.histogramVec("http_request_size_bytes", "Histogram of HTTP request body sizes", sizeBuckets, "path", "method"),
+		HTTPResponseSize:  o.histogramVec("http_response_size_bytes", "Histogram of HTTP response body sizes", sizeBuckets, "path", "method"),
+		HTTPInFlight:      o.gaugeVec("http_requests_in_flight", "Number of HTTP requests currently being served", "path"),
+
+		SLOErrorBudgetRemaining: o.gaugeVec("slo_error_budget_remaining_ratio", "Share of the 30-day error budget left (negative when exhausted)", "path", "sli"),
+		SLOBurnRate:             o.gaugeVec("slo_burn_rate", "Error budget burn rate over a rolling window (1 spends the budget exactly in 30 days)", "path", "sli", "window"),
+
+		Uptime:             o.gauge("application_uptime_seconds", "Application uptime in seconds"),
+		Downtime:           o.gauge("application_downtime_seconds", "Application downtime in seconds over the last 30 days"),
+		AvailabilityRate:   o.gauge("application_availability_rate", "Application availability rate in percentage over the last 30 days"),
+		AvailabilityWindow: o.gaugeVec("application_availability_window_rate", "Application availability rate in percentage over a rolling window", "window"),
+
+		CPUUsage:    o.gauge("cpu_usage_percent", "Process CPU usage as a percentage of one core"),
+		MemoryUsage: o.gauge("memory_usage_bytes", "Resident memory (RSS) of the process in bytes"),
+		Goroutines:  o.gauge("goroutines_count", "Number of goroutines currently running"),
+		HeapAlloc:   o.gauge("memory_heap_alloc_bytes", "Bytes of allocated heap objects"),
+		OpenFDs:     o.gauge("open_file_descriptors", "Number of open file descriptors held by the process"),
+		GCLastPause: o.gauge("gc_last_pause_seconds", "Duration of the most recent garbage collection pause"),
+	}
+	m.GCCycles = o.counterFunc("gc_cycles_total", "Number of completed garbage collection cycles", func() float64 {
+		return float64(m.gcCycles.Load())
 	})
-	MemoryUsage = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "memory_usage_bytes",
-		Help: "Memory usage in bytes",
+	m.GCPauseTotal = o.counterFunc("gc_pause_seconds_total", "Cumulative time spent in garbage collection stop-the-world pauses", func() float64 {
+		return time.Duration(m.gcPauseNanos.Load()).Seconds()
 	})
-)
+	return m
+}
 
-// Queue length gauge.
-var QueueLength = prometheus.NewGauge(prometheus.GaugeOpts{
-	Name: "queue_length",
-	Help: "Length of the processing queue",
-})
-
-// Database metrics.
-var (
-	DBQueryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
-		Name:    "db_query_duration_seconds",
-		Help:    "Duration of database queries",
-		Buckets: prometheus.DefBuckets,
+// define records a metric definition so dashboards can be generated from it.
+func (o *options) define(kind metricType, name, help string, labels ...string) {
+	o.definitions = append(o.definitions, definition{
+		name:   prometheus.BuildFQName(o.namespace, o.subsystem, name),
+		base:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
 	})
-	DBOpenConnections = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "db_open_connections",
-		Help: "Number of open database connections",
-	})
-)
+}
+
+func (o *options) gauge(name, help string) prometheus.Gauge {
+	o.define(typeGauge, name, help)
+	return prometheus.NewGauge(prometheus.GaugeOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels})
+}
+
+func (o *options) gaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	o.define(typeGauge, name, help, labels...)
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels}, labels)
+}
+
+func (o *options) counter(name, help string) prometheus.Counter {
+	o.define(typeCounter, name, help)
+	return prometheus.NewCounter(prometheus.CounterOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels})
+}
+
+func (o *options) counterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	o.define(typeCounter, name, help, labels...)
+	return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels}, labels)
+}
+
+func (o *options) gaugeFunc(name, help string, fn func() float64) prometheus.GaugeFunc {
+	o.define(typeGauge, name, help)
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels}, fn)
+}
+
+func (o *options) counterFunc(name, help string, fn func() float64) prometheus.CounterFunc {
+	o.define(typeCounter, name, help)
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels}, fn)
+}
 
-// Throughput counter for processed items.
-var ThroughputCounter = prometheus.NewCounter(prometheus.CounterOpts{
-	Name: "throughput_total",
-	Help: "Total number of processed items (throughput)",
-})
+func (o *options) histogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	o.define(typeHistogram, name, help, labels...)
+	return prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: o.namespace, Subsystem: o.subsystem, Name: name, Help: help, ConstLabels: o.constLabels, Buckets: buckets}, labels)
+}
+
+// collectors returns the collectors created by New.
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.HTTPDuration, m.HTTPRequestCount, m.HTTPErrorCount, m.HTTPStatusCounter,
+		m.HTTPRequestSize, m.HTTPResponseSize, m.HTTPInFlight,
+		m.SLOErrorBudgetRemaining, m.SLOBurnRate,
+		m.Uptime, m.Downtime, m.AvailabilityRate, m.AvailabilityWindow,
+		m.CPUUsage, m.MemoryUsage, m.Goroutines, m.HeapAlloc, m.OpenFDs, m.GCCycles, m.GCPauseTotal, m.GCLastPause,
+	}
+}
+
+// -----------------------
+// Application Metrics
+// -----------------------
+
+// NewGauge adds an application gauge. Like every metric added through the New methods,
+// it gets the instance's namespace, subsystem and constant labels, appears in the generated
+// dashboard and is registered by Register, or right away if Register was already called.
+// Adding a name twice panics once registered.
+func (m *Metrics) NewGauge(name, help string) prometheus.Gauge {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.gauge(name, help))
+}
+
+// NewGaugeVec adds an application gauge with labels.
+func (m *Metrics) NewGaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.gaugeVec(name, help, labels...))
+}
+
+// NewGaugeFunc adds an application gauge whose value is read from fn on every scrape.
+func (m *Metrics) NewGaugeFunc(name, help string, fn func() float64) prometheus.GaugeFunc {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.gaugeFunc(name, help, fn))
+}
+
+// NewCounter adds an application counter.
+func (m *Metrics) NewCounter(name, help string) prometheus.Counter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.counter(name, help))
+}
+
+// NewCounterVec adds an application counter with labels.
+func (m *Metrics) NewCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.counterVec(name, help, labels...))
+}
+
+// NewHistogramVec adds an application histogram with labels. Nil buckets use the
+// buckets set with WithBuckets.
+func (m *Metrics) NewHistogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	if buckets == nil {
+		buckets = m.httpBuckets
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return add(m, m.opts.histogramVec(name, help, buckets, labels...))
+}
+
+// AddDashboardSection adds a dashboard row, after the built-in ones, for the metrics whose
+// names start with one of prefixes (without namespace and subsystem).
+func (m *Metrics) AddDashboardSection(title string, prefixes ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sections = append(m.sections, dashboardSection{title: title, prefixes: prefixes})
+}
+
+// add records an application collector and registers it if Register already ran.
+// m.mu must be held.
+func add[C prometheus.Collector](m *Metrics, c C) C {
+	m.added = append(m.added, c)
+	if m.registered {
+		m.registerer.MustRegister(c)
+	}
+	return c
+}
 
 // -----------------------
 // Initialization & Update Functions
 // -----------------------
 
-// RegisterMetrics registers all the defined metrics with the custom registry.
-func RegisterMetrics() {
-	customRegistry.MustRegister(HTTPDuration)
-	customRegistry.MustRegister(HTTPRequestCount)
-	customRegistry.MustRegister(HTTPErrorCount)
-	customRegistry.MustRegister(HTTPStatusCounter)
-	customRegistry.MustRegister(Uptime)
-	customRegistry.MustRegister(Downtime)
-	customRegistry.MustRegister(AvailabilityRate)
-	customRegistry.MustRegister(CPUUsage)
-	customRegistry.MustRegister(MemoryUsage)
-	customRegistry.MustRegister(QueueLength)
-	customRegistry.MustRegister(DBQueryDuration)
-	customRegistry.MustRegister(DBOpenConnections)
-	customRegistry.MustRegister(ThroughputCounter)
-}
-
-// MetricsHandler returns an HTTP handler for exposing metrics from the custom registry.
-func MetricsHandler() http.Handler {
-	return promhttp.HandlerFor(customRegistry, promhttp.HandlerOpts{})
-}
-
-// StartUptime begins a background routine to update the uptime gauge.
-func StartUptime() {
-	startTime := time.Now()
-	go func() {
-		for {
-			uptime := time.Since(startTime).Seconds()
-			Uptime.Set(uptime)
-			// For demonstration, assume downtime is 0.
-			Downtime.Set(0)
-			availability := 100.0
-			if uptime > 0 {
-				availability = (uptime / uptime) * 100.0
+// Register registers all collectors, including those added by the application so far,
+// with the configured registerer. Collectors added later are registered as they are added.
+// It is safe to call more than once; later calls return the result of the first.
+func (m *Metrics) Register() error {
+	m.registerOnce.Do(func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, c := range append(m.collectors(), m.added...) {
+			if err := m.registerer.Register(c); err != nil {
+				m.registerErr = err
+				return
 			}
-			AvailabilityRate.Set(availability)
-			time.Sleep(1 * time.Second)
 		}
-	}()
-}
-
-// UpdateSystemMetrics updates CPU and memory usage periodically.
-func UpdateSystemMetrics() {
-	go func() {
-		for {
-			// Replace dummy CPU usage with actual metrics if available.
-			CPUUsage.Set(10.0)
-			var m runtime.MemStats
-			runtime.ReadMemStats(&m)
-			MemoryUsage.Set(float64(m.Alloc))
-			time.Sleep(10 * time.Second)
-		}
-	}()
+		m.registered = true
+	})
+	return m.registerErr
 }
 
-// RecordHTTPMetrics is a helper function to record HTTP metrics.
-// It should be called with the request's route pattern, HTTP method, the start time,
+// Handler returns an HTTP handler exposing the metrics from the configured gatherer.
+// OpenMetrics is enabled so trace ID exemplars are exposed to scrapers that ask for them.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// Wait blocks until the background routines have exited after their context was cancelled.
+// The uptime routine writes its final heartbeat before exiting.
+func (m *Metrics) Wait() {
+	m.background.Wait()
+}
+
+// Gatherer returns the gatherer the metrics are exposed from.
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	return m.gatherer
+}
+
+// RecordHTTPMetrics records HTTP metrics for a finished request.
+// It should be called with the request context, route pattern, HTTP method, the start time,
 // and a flag indicating whether the request resulted in an error.
-func RecordHTTPMetrics(path, method string, start time.Time, isError bool) {
+func (m *Metrics) RecordHTTPMetrics(ctx context.Context, path, method string, start time.Time, isError bool) {
 	duration := time.Since(start).Seconds()
-	HTTPDuration.WithLabelValues(path, method).Observe(duration)
-	HTTPRequestCount.WithLabelValues(path, method).Inc()
+	ObserveWithTrace(ctx, m.HTTPDuration.WithLabelValues(path, method), duration)
+	m.HTTPRequestCount.WithLabelValues(path, method).Inc()
 	if isError {
-		HTTPErrorCount.WithLabelValues(path, method).Inc()
+		m.HTTPErrorCount.WithLabelValues(path, method).Inc()
 	}
 }
 
-// RecordDBQuery records a database query duration.
-func RecordDBQuery(duration time.Duration) {
-	DBQueryDuration.Observe(duration.Seconds())
-}
-
-// UpdateDBConnections sets the current number of open database connections.
-func UpdateDBConnections(conns int) {
-	DBOpenConnections.Set(float64(conns))
+// ObserveWithTrace observes value, attaching the trace ID from ctx as an exemplar when there is one.
+func ObserveWithTrace(ctx context.Context, observer prometheus.Observer, value float64) {
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(value, prometheus.Labels{"trace_id": traceID})
+			return
+		}
+	}
+	observer.Observe(value)
 }
 
 // -----------------------
 // HTTP Metrics Middleware for net/http
 // -----------------------
 
-// responseWriter is a wrapper around http.ResponseWriter to capture the status code.
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
-
-// WriteHeader intercepts the WriteHeader call to capture the HTTP status code.
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
-
 // HTTPMetricsMiddlewareWithPattern returns a middleware that wraps HTTP requests
-// and records metrics using a user-supplied route pattern.
-func HTTPMetricsMiddlewareWithPattern(pattern string) func(http.Handler) http.Handler {
+// in a server tracing span and records metrics using a user-supplied route pattern.
+func (m *Metrics) HTTPMetricsMiddlewareWithPattern(pattern string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			// Continue the caller's trace if it sent a traceparent header.
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+pattern,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(pattern),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+			r = r.WithContext(ctx)
+
+			inFlight := m.HTTPInFlight.WithLabelValues(pattern)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			body := &countingReader{ReadCloser: r.Body}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = body
+			}
+
 			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 			next.ServeHTTP(rw, r)
+
+			requestSize := body.n
+			if r.ContentLength > requestSize {
+				// The handler may not have read the whole body.
+				requestSize = r.ContentLength
+			}
+			m.HTTPRequestSize.WithLabelValues(pattern, r.Method).Observe(float64(requestSize))
+			m.HTTPResponseSize.WithLabelValues(pattern, r.Method).Observe(float64(rw.bytes))
 			// Determine if this is an error.
 			isError := rw.statusCode >= http.StatusBadRequest
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rw.statusCode))
+			if rw.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.statusCode))
+			}
 			// Record basic HTTP metrics.
-			RecordHTTPMetrics(pattern, r.Method, start, isError)
+			m.RecordHTTPMetrics(ctx, pattern, r.Method, start, isError)
 			// Record the HTTP status code in a separate counter.
-			HTTPStatusCounter.WithLabelValues(pattern, r.Method, strconv.Itoa(rw.statusCode)).Inc()
+			m.HTTPStatusCounter.WithLabelValues(pattern, r.Method, strconv.Itoa(rw.statusCode)).Inc()
 		})
 	}
 }
 
 // InstrumentHandler wraps an http.Handler with HTTP metrics instrumentation using the provided route pattern.
-func InstrumentHandler(pattern string, handler http.Handler) http.Handler {
-	return HTTPMetricsMiddlewareWithPattern(pattern)(handler)
+func (m *Metrics) InstrumentHandler(pattern string, handler http.Handler) http.Handler {
+	return m.HTTPMetricsMiddlewareWithPattern(pattern)(handler)
 }
@@ -0,0 +1,293 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sloBudgetPeriod is the period the error budget is computed over.
+const sloBudgetPeriod = 30 * 24 * time.Hour
+
+// sloWindows are the burn-rate windows, pairing short and long windows as in
+// the usual multi-window alerting setup (5m/1h, 30m/6h, 6h/3d).
+var sloWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"5m", 5 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"1d", 24 * time.Hour},
+	{"3d", 3 * 24 * time.Hour},
+}
+
+// SLI kinds.
+const (
+	SLIAvailability = "availability" // Share of requests that did not return a 5xx.
+	SLILatency      = "latency"      // Share of requests served within the latency threshold.
+)
+
+// SLO declares objectives for one route pattern, as registered with InstrumentHandler.
+// A zero target disables that objective.
+type SLO struct {
+	Route              string
+	AvailabilityTarget float64       // Percentage of non-5xx responses, e.g. 99.5.
+	LatencyThreshold   time.Duration // Requests at or under this duration are good.
+	LatencyTarget      float64       // Percentage of requests within LatencyThreshold, e.g. 99.
+}
+
+// SLOStatus is the current state of one objective, as served by GET /admin/slo.
+type SLOStatus struct {
+	Route                string             `json:"route"`
+	SLI                  string             `json:"sli"`
+	Target               float64            `json:"target"`
+	LatencyThreshold     string             `json:"latency_threshold,omitempty"`
+	TotalRequests        float64            `json:"total_requests"`
+	BadRequests          float64            `json:"bad_requests"`
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"`
+	BurnRates            map[string]float64 `json:"burn_rates"`
+}
+
+// sloSample is a cumulative (total, bad) reading at a point in time.
+type sloSample struct {
+	at         time.Time
+	total, bad float64
+}
+
+// objective is a single SLI tracked for a route, with its sample history.
+type objective struct {
+	route     string
+	sli       string
+	target    float64 // Percentage.
+	threshold time.Duration
+	bound     float64 // Histogram bucket upper bound used for latency, in seconds.
+	samples   []sloSample
+}
+
+// sloTracker periodically samples the HTTP metrics and derives SLO gauges from them.
+type sloTracker struct {
+	mu         sync.Mutex
+	objectives []*objective
+}
+
+// StartSLOs samples the HTTP metrics for the given objectives every interval until ctx
+// is cancelled, updating the error budget and burn-rate gauges.
+// Latency thresholds are rounded down to the nearest HTTP duration bucket boundary, or up
+// to the smallest boundary when they are below it.
+//
+// The sample history is kept in memory for this process only: it starts empty on every
+// restart, so the budget and burn rates cover at most the time since then, and each
+// replica only sees its own traffic. For fleet-wide or restart-proof SLOs, compute them
+// in Prometheus from the HTTP metrics instead (see the recording rules in the README).
+func (m *Metrics) StartSLOs(ctx context.Context, slos []SLO, interval time.Duration) {
+	if len(slos) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	tracker := &sloTracker{}
+	for _, s := range slos {
+		if s.AvailabilityTarget > 0 {
+			tracker.objectives = append(tracker.objectives, &objective{route: s.Route, sli: SLIAvailability, target: s.AvailabilityTarget})
+		}
+		if s.LatencyTarget > 0 && s.LatencyThreshold > 0 {
+			bound := m.latencyBound(s.LatencyThreshold)
+			if bound != s.LatencyThreshold.Seconds() {
+				slog.WarnContext(ctx, "SLO latency threshold is not a bucket boundary", "route", s.Route, "threshold", s.LatencyThreshold, "bound", bound)
+			}
+			tracker.objectives = append(tracker.objectives, &objective{route: s.Route, sli: SLILatency, target: s.LatencyTarget, threshold: s.LatencyThreshold, bound: bound})
+		}
+	}
+	m.sloMu.Lock()
+	m.slo = tracker
+	m.sloMu.Unlock()
+
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.sampleSLOs(tracker, time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SLOStatuses returns the current state of every tracked objective.
+func (m *Metrics) SLOStatuses() []SLOStatus {
+	m.sloMu.Lock()
+	tracker := m.slo
+	m.sloMu.Unlock()
+	if tracker == nil {
+		return nil
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	statuses := make([]SLOStatus, 0, len(tracker.objectives))
+	for _, o := range tracker.objectives {
+		statuses = append(statuses, o.status())
+	}
+	return statuses
+}
+
+// sampleSLOs reads the current HTTP counters, appends a sample per objective and updates the gauges.
+func (m *Metrics) sampleSLOs(tracker *sloTracker, now time.Time) {
+	requests := collect(m.HTTPRequestCount)
+	statuses := collect(m.HTTPStatusCounter)
+	durations := collect(m.HTTPDuration)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	for _, o := range tracker.objectives {
+		var total, bad float64
+		switch o.sli {
+		case SLIAvailability:
+			for _, metric := range requests {
+				if labelValue(metric, "path") == o.route {
+					total += metric.GetCounter().GetValue()
+				}
+			}
+			for _, metric := range statuses {
+				code, _ := strconv.Atoi(labelValue(metric, "code"))
+				if labelValue(metric, "path") == o.route && code >= 500 {
+					bad += metric.GetCounter().GetValue()
+				}
+			}
+		case SLILatency:
+			for _, metric := range durations {
+				if labelValue(metric, "path") != o.route {
+					continue
+				}
+				h := metric.GetHistogram()
+				total += float64(h.GetSampleCount())
+				good := 0.0
+				for _, b := range h.GetBucket() {
+					if b.GetUpperBound() == o.bound {
+						good = float64(b.GetCumulativeCount())
+					}
+				}
+				bad += float64(h.GetSampleCount()) - good
+			}
+		}
+
+		o.samples = append(o.samples, sloSample{at: now, total: total, bad: bad})
+		o.prune(now)
+
+		status := o.status()
+		m.SLOErrorBudgetRemaining.WithLabelValues(o.route, o.sli).Set(status.ErrorBudgetRemaining)
+		for window, rate := range status.BurnRates {
+			m.SLOBurnRate.WithLabelValues(o.route, o.sli, window).Set(rate)
+		}
+	}
+}
+
+// latencyBound returns the largest HTTP duration bucket boundary not above threshold,
+// or the smallest boundary when threshold is below all of them.
+func (m *Metrics) latencyBound(threshold time.Duration) float64 {
+	bound, smallest := 0.0, math.Inf(1)
+	for _, b := range m.httpBuckets {
+		if b <= threshold.Seconds() && b > bound {
+			bound = b
+		}
+		smallest = math.Min(smallest, b)
+	}
+	if bound == 0 {
+		return smallest
+	}
+	return bound
+}
+
+// status computes the budget and burn rates from the sample history.
+func (o *objective) status() SLOStatus {
+	s := SLOStatus{Route: o.route, SLI: o.sli, Target: o.target, BurnRates: make(map[string]float64)}
+	if o.threshold > 0 {
+		s.LatencyThreshold = o.threshold.String()
+	}
+	if len(o.samples) == 0 {
+		s.ErrorBudgetRemaining = 1
+		return s
+	}
+
+	allowed := 1 - o.target/100
+	latest := o.samples[len(o.samples)-1]
+	first := o.samples[0]
+	s.TotalRequests = latest.total - first.total
+	s.BadRequests = latest.bad - first.bad
+	s.ErrorBudgetRemaining = 1 - errorRatio(s.TotalRequests, s.BadRequests)/allowed
+
+	for _, w := range sloWindows {
+		base := o.sampleAt(latest.at.Add(-w.duration))
+		s.BurnRates[w.label] = errorRatio(latest.total-base.total, latest.bad-base.bad) / allowed
+	}
+	return s
+}
+
+// sampleAt returns the oldest sample taken at or after t, or the first sample.
+func (o *objective) sampleAt(t time.Time) sloSample {
+	i := sort.Search(len(o.samples), func(i int) bool { return !o.samples[i].at.Before(t) })
+	if i == len(o.samples) {
+		i = len(o.samples) - 1
+	}
+	return o.samples[i]
+}
+
+// prune drops samples older than the budget period.
+func (o *objective) prune(now time.Time) {
+	cutoff := now.Add(-sloBudgetPeriod)
+	i := sort.Search(len(o.samples), func(i int) bool { return o.samples[i].at.After(cutoff) })
+	if i > 0 {
+		o.samples = append(o.samples[:0], o.samples[i:]...)
+	}
+}
+
+// errorRatio returns bad/total, or 0 when there was no traffic.
+func errorRatio(total, bad float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Max(0, bad) / total
+}
+
+// collect reads the current values of every series of a collector.
+func collect(c prometheus.Collector) []*dto.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	var metrics []*dto.Metric
+	for metric := range ch {
+		var out dto.Metric
+		if err := metric.Write(&out); err == nil {
+			metrics = append(metrics, &out)
+		}
+	}
+	return metrics
+}
+
+// labelValue returns the value of a label on a collected metric.
+func labelValue(metric *dto.Metric, name string) string {
+	for _, pair := range metric.GetLabel() {
+		if pair.GetName() == name {
+			return pair.GetValue()
+		}
+	}
+	return ""
+}
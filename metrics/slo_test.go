@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLatencyBound(t *testing.T) {
+	m := New(WithBuckets([]float64{0.05, 0.1, 0.25, 0.5, 1}))
+	tests := []struct {
+		threshold time.Duration
+		want      float64
+	}{
+		{250 * time.Millisecond, 0.25},      // A bucket boundary.
+		{300 * time.Millisecond, 0.25},      // Rounded down.
+		{999 * time.Millisecond, 0.5},       // Rounded down.
+		{50 * time.Millisecond, 0.05},       // The smallest boundary.
+		{10 * time.Millisecond, 0.05},       // Below every boundary: the smallest one.
+		{time.Nanosecond, 0.05},             // Below every boundary: the smallest one.
+		{10 * time.Second, 1},               // Above every boundary: the largest one.
+		{time.Second + time.Millisecond, 1}, // Just above the largest boundary.
+		{100*time.Millisecond - time.Nanosecond, 0.05},
+	}
+	for _, tt := range tests {
+		t.Run(tt.threshold.String(), func(t *testing.T) {
+			if got := m.latencyBound(tt.threshold); got != tt.want {
+				t.Errorf("latencyBound(%s) = %g, want %g", tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectiveStatus(t *testing.T) {
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int, total, bad float64) sloSample {
+		return sloSample{at: start.Add(time.Duration(minutes) * time.Minute), total: total, bad: bad}
+	}
+
+	tests := []struct {
+		name      string
+		objective objective
+		wantTotal float64
+		wantBad   float64
+		wantLeft  float64
+		wantBurn  map[string]float64 // Windows not listed must have a burn rate of 0.
+	}{
+		{
+			name:      "no samples",
+			objective: objective{sli: SLIAvailability, target: 99},
+			wantLeft:  1,
+		},
+		{
+			name:      "no traffic",
+			objective: objective{sli: SLIAvailability, target: 99, samples: []sloSample{at(0, 50, 1), at(60, 50, 1)}},
+			wantLeft:  1,
+		},
+		{
+			name:      "no errors",
+			objective: objective{sli: SLIAvailability, target: 99.5, samples: []sloSample{at(0, 0, 0), at(60, 1000, 0)}},
+			wantTotal: 1000,
+			wantLeft:  1,
+		},
+		{
+			name:      "half the budget spent",
+			objective: objective{sli: SLIAvailability, target: 99, samples: []sloSample{at(0, 0, 0), at(60, 1000, 5)}},
+			wantTotal: 1000,
+			wantBad:   5,
+			wantLeft:  0.5,
+			wantBurn:  map[string]float64{"1h": 0.5, "6h": 0.5, "1d": 0.5, "3d": 0.5},
+		},
+		{
+			name: "budget exhausted, burning faster recently",
+			objective: objective{sli: SLILatency, target: 99, threshold: 250 * time.Millisecond,
+				samples: []sloSample{at(0, 0, 0), at(30, 100, 0), at(60, 300, 6)}},
+			wantTotal: 300,
+			wantBad:   6,
+			wantLeft:  -1,
+			wantBurn:  map[string]float64{"30m": 3, "1h": 2, "6h": 2, "1d": 2, "3d": 2},
+		},
+		{
+			name:      "counters ahead of the first sample are not counted",
+			objective: objective{sli: SLIAvailability, target: 90, samples: []sloSample{at(0, 500, 50), at(5, 600, 60)}},
+			wantTotal: 100,
+			wantBad:   10,
+			wantLeft:  0,
+			wantBurn:  map[string]float64{"5m": 1, "30m": 1, "1h": 1, "6h": 1, "1d": 1, "3d": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.objective.status()
+			if s.TotalRequests != tt.wantTotal || s.BadRequests != tt.wantBad {
+				t.Errorf("requests = %g total, %g bad; want %g, %g", s.TotalRequests, s.BadRequests, tt.wantTotal, tt.wantBad)
+			}
+			if !approx(s.ErrorBudgetRemaining, tt.wantLeft) {
+				t.Errorf("ErrorBudgetRemaining = %g, want %g", s.ErrorBudgetRemaining, tt.wantLeft)
+			}
+			if len(tt.objective.samples) == 0 {
+				if len(s.BurnRates) != 0 {
+					t.Errorf("BurnRates = %v, want none without samples", s.BurnRates)
+				}
+				return
+			}
+			for _, w := range sloWindows {
+				if got, want := s.BurnRates[w.label], tt.wantBurn[w.label]; !approx(got, want) {
+					t.Errorf("burn rate %s = %g, want %g", w.label, got, want)
+				}
+			}
+			if tt.objective.threshold > 0 && s.LatencyThreshold != tt.objective.threshold.String() {
+				t.Errorf("LatencyThreshold = %q, want %q", s.LatencyThreshold, tt.objective.threshold)
+			}
+		})
+	}
+}
+
+func approx(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/procfs"
+)
+
+// UpdateSystemMetrics samples process CPU, memory, goroutine, GC and file descriptor
+// metrics every interval until ctx is cancelled.
+// CPU usage is the share of one core used since the previous sample, so it can exceed
+// 100 on multi-core machines. On platforms without /proc only the runtime metrics are set.
+func (m *Metrics) UpdateSystemMetrics(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastCPU float64
+		var lastSample time.Time
+		procAvailable := true
+		for {
+			if procAvailable {
+				cpu, err := m.sampleProc()
+				if err != nil {
+					slog.WarnContext(ctx, "Process metrics unavailable, reporting runtime metrics only", "error", err)
+					procAvailable = false
+				} else {
+					now := time.Now()
+					if !lastSample.IsZero() {
+						elapsed := now.Sub(lastSample).Seconds()
+						if elapsed > 0 {
+							m.CPUUsage.Set((cpu - lastCPU) / elapsed * 100.0)
+						}
+					}
+					lastCPU, lastSample = cpu, now
+				}
+			}
+			m.sampleRuntime()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sampleProc reads RSS and open file descriptors from /proc and returns
+// the total CPU time consumed by the process in seconds.
+func (m *Metrics) sampleProc() (float64, error) {
+	proc, err := procfs.Self()
+	if err != nil {
+		return 0, err
+	}
+	stat, err := proc.Stat()
+	if err != nil {
+		return 0, err
+	}
+	m.MemoryUsage.Set(float64(stat.ResidentMemory()))
+	if fds, err := proc.FileDescriptorsLen(); err == nil {
+		m.OpenFDs.Set(float64(fds))
+	}
+	return stat.CPUTime(), nil
+}
+
+// sampleRuntime updates goroutine, heap and garbage collection metrics.
+func (m *Metrics) sampleRuntime() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	m.Goroutines.Set(float64(runtime.NumGoroutine()))
+	m.HeapAlloc.Set(float64(ms.Alloc))
+	m.gcCycles.Store(uint64(ms.NumGC))
+	m.gcPauseNanos.Store(ms.PauseTotalNs)
+	if ms.NumGC > 0 {
+		m.GCLastPause.Set(time.Duration(ms.PauseNs[(ms.NumGC+255)%256]).Seconds())
+	}
+}
@@ -0,0 +1,96 @@
+// Package redact removes secrets from strings before they reach logs, error
+// responses or configuration dumps.
+package redact
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Placeholder replaces redacted values.
+const Placeholder = "[REDACTED]"
+
+// minSecretLength keeps short values (which would match too much text) from being registered.
+const minSecretLength = 4
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// patterns match secrets that look like secrets even when they were never registered:
+// API keys in query strings and passwords in connection URLs.
+var patterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)((?:api_?key|token|password|secret)=)[^&\s"']+`), "${1}" + Placeholder},
+	{regexp.MustCompile(`(://[^:/@\s"']+:)[^@\s"']+@`), "${1}" + Placeholder + "@"},
+}
+
+// Register adds secret values to redact wherever they appear. Empty and very short values are ignored.
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLength {
+			continue
+		}
+		known := false
+		for _, s := range secrets {
+			if s == v {
+				known = true
+				break
+			}
+		}
+		if !known {
+			secrets = append(secrets, v)
+		}
+	}
+}
+
+// String returns s with registered secrets and secret-looking values replaced by Placeholder.
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	mu.RUnlock()
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// Error returns err with its message redacted, or nil. The original error is kept
+// for errors.Is and errors.As.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// URLPassword returns the password embedded in a connection URL, or "" if there is none.
+func URLPassword(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	password, _ := u.User.Password()
+	return password
+}
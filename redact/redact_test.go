@@ -0,0 +1,118 @@
+package redact
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+// withSecrets registers values for the duration of the test only.
+func withSecrets(t *testing.T, values ...string) {
+	t.Helper()
+	mu.Lock()
+	saved := secrets
+	secrets = nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		secrets = saved
+		mu.Unlock()
+	})
+	Register(values...)
+}
+
+func TestString(t *testing.T) {
+	withSecrets(t, "s3cr3t-key", "abc", "hunter22")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no secrets", "fetch failed: 503 Service Unavailable", "fetch failed: 503 Service Unavailable"},
+		{"registered secret", "using key s3cr3t-key", "using key [REDACTED]"},
+		{"every occurrence", "s3cr3t-key and s3cr3t-key", "[REDACTED] and [REDACTED]"},
+		{"several secrets", "s3cr3t-key/hunter22", "[REDACTED]/[REDACTED]"},
+		{"short values are not registered", "abc def", "abc def"},
+		{"apikey in a query string", "GET /query?function=X&apikey=ZZZ123&datatype=json", "GET /query?function=X&apikey=[REDACTED]&datatype=json"},
+		{"api_key, any case", "API_KEY=ZZZ123 next", "API_KEY=[REDACTED] next"},
+		{"token", "token=abc.def", "token=[REDACTED]"},
+		{"password parameter", `dsn "host=db password=pw1"`, `dsn "host=db password=[REDACTED]"`},
+		{"password in a connection URL", "postgres://app:pw@db:5432/rates", "postgres://app:[REDACTED]@db:5432/rates"},
+		{"URL without a password", "postgres://app@db:5432/rates", "postgres://app@db:5432/rates"},
+		{"registered secret inside a URL", "https://host/q?apikey=s3cr3t-key", "https://host/q?apikey=[REDACTED]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterIgnoresEmptyAndDuplicates(t *testing.T) {
+	withSecrets(t, "", "s3cr3t-key", "s3cr3t-key")
+	Register("s3cr3t-key")
+	if len(secrets) != 1 {
+		t.Errorf("secrets = %q, want only s3cr3t-key", secrets)
+	}
+}
+
+func TestError(t *testing.T) {
+	withSecrets(t, "s3cr3t-key")
+	wrapped := &fs.PathError{Op: "open", Path: "/run/secrets/s3cr3t-key", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		same    bool // Whether the error is returned unchanged.
+		matches error
+	}{
+		{name: "nil", err: nil},
+		{name: "nothing to redact", err: fs.ErrPermission, want: fs.ErrPermission.Error(), same: true, matches: fs.ErrPermission},
+		{name: "redacted and still unwraps", err: wrapped, want: "open /run/secrets/[REDACTED]: file does not exist", matches: fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Error(tt.err)
+			if tt.err == nil {
+				if got != nil {
+					t.Fatalf("Error(nil) = %v, want nil", got)
+				}
+				return
+			}
+			if got.Error() != tt.want {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.want)
+			}
+			if (got == tt.err) != tt.same {
+				t.Errorf("Error() returned the original error: %v, want %v", got == tt.err, tt.same)
+			}
+			if !errors.Is(got, tt.matches) {
+				t.Errorf("errors.Is(%v, %v) = false", got, tt.matches)
+			}
+		})
+	}
+}
+
+func TestURLPassword(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"postgres://app:pw@db:5432/rates", "pw"},
+		{"postgres://app:p%40ss@db/rates", "p@ss"},
+		{"postgres://app@db/rates", ""},
+		{"postgres://db/rates", ""},
+		{"host=db user=app", ""},
+		{"://bad", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := URLPassword(tt.url); got != tt.want {
+				t.Errorf("URLPassword(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
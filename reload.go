@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+	"federal-funds-rate-metrics-ByYear/config"
+	"federal-funds-rate-metrics-ByYear/handle"
+	"federal-funds-rate-metrics-ByYear/jobs"
+	"federal-funds-rate-metrics-ByYear/logging"
+	"federal-funds-rate-metrics-ByYear/source"
+)
+
+// Reload triggers and results, as recorded in the config_reloads_total metric.
+const (
+	triggerSignal = "signal"
+	triggerFile   = "file"
+
+	reloadApplied   = "applied"
+	reloadUnchanged = "unchanged"
+	reloadRejected  = "rejected"
+	reloadFailed    = "failed"
+)
+
+// reloader re-reads the configuration on SIGHUP and when the config file or a secret
+// file changes, and applies the settings that are safe to change while serving:
+// the log level, upstream timeouts, retries, rate and breaker limits, the API key and the
+// refresh and snapshot schedules. A reload that changes any other setting is rejected
+// as a whole and the running configuration is kept.
+type reloader struct {
+	args     []string // Command-line flags, re-applied on every reload.
+	upstream *source.Client
+	queue    *jobs.Queue
+
+	mu      sync.Mutex
+	current *config.Config
+	wg      sync.WaitGroup
+}
+
+func newReloader(args []string, current *config.Config, upstream *source.Client, queue *jobs.Queue) *reloader {
+	return &reloader{args: args, current: current, upstream: upstream, queue: queue}
+}
+
+// Start listens for SIGHUP and watches the configuration files until ctx is cancelled.
+func (r *reloader) Start(ctx context.Context) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer signal.Stop(hangups)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangups:
+				r.reload(ctx, triggerSignal)
+			}
+		}
+	}()
+
+	// Files added by a later reload (e.g. a new secret file) are only watched after a restart.
+	files := r.current.WatchedFiles()
+	if len(files) == 0 {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := config.Watch(ctx, files, func() { r.reload(ctx, triggerFile) }); err != nil {
+			slog.Warn("Config files are not watched; send SIGHUP to reload", "error", err)
+		}
+	}()
+}
+
+// Wait blocks until the signal handler and the file watcher have exited.
+func (r *reloader) Wait() {
+	r.wg.Wait()
+}
+
+// reload loads the configuration again and applies it if only live settings changed.
+func (r *reloader) reload(ctx context.Context, trigger string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := config.Load(r.args)
+	if err != nil {
+		slog.Error("Configuration reload failed; keeping the running configuration", "trigger", trigger, "error", err)
+		appmetrics.RecordConfigReload(trigger, reloadFailed)
+		return
+	}
+
+	live, restart := config.Changes(r.current, next)
+	switch {
+	case len(restart) > 0:
+		slog.Warn("Configuration reload rejected: changed settings require a restart",
+			"trigger", trigger, "restart_required", restart, "live", live)
+		appmetrics.RecordConfigReload(trigger, reloadRejected)
+		return
+	case len(live) == 0:
+		slog.Info("Configuration reloaded without changes", "trigger", trigger)
+		appmetrics.RecordConfigReload(trigger, reloadUnchanged)
+		return
+	}
+
+	if err := logging.SetLevel(next.LogLevel); err != nil {
+		// Load already validated the level, so this only happens on a programming error.
+		slog.Error("Configuration reload failed; keeping the running configuration", "trigger", trigger, "error", err)
+		appmetrics.RecordConfigReload(trigger, reloadFailed)
+		return
+	}
+	r.upstream.SetAPIKey(next.APIKey)
+	r.upstream.SetOptions(upstreamOptions(next))
+	r.queue.Schedule(ctx, jobs.KindRefresh, next.RefreshInterval)
+	r.queue.Schedule(ctx, jobs.KindExport, next.ExportInterval)
+	handle.InitConfig(next, r.upstream)
+	r.current = next
+
+	slog.Info("Configuration reloaded", "trigger", trigger, "changed", live)
+	appmetrics.RecordConfigReload(trigger, reloadApplied)
+}
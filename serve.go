@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+
+	"federal-funds-rate-metrics-ByYear/config"
+	"federal-funds-rate-metrics-ByYear/db"
+	"federal-funds-rate-metrics-ByYear/export"
+	"federal-funds-rate-metrics-ByYear/handle"
+	"federal-funds-rate-metrics-ByYear/health"
+	"federal-funds-rate-metrics-ByYear/jobs"
+	"federal-funds-rate-metrics-ByYear/logging"
+	"federal-funds-rate-metrics-ByYear/metrics"
+	"federal-funds-rate-metrics-ByYear/services"
+	"federal-funds-rate-metrics-ByYear/source"
+	"federal-funds-rate-metrics-ByYear/tracing"
+)
+
+// serve starts the server and blocks until SIGINT or SIGTERM, then shuts down in order:
+// stop accepting and drain in-flight requests, stop background routines and the
+// job queue, then close the database pool and flush traces.
+// args are command-line flags overriding the file and environment configuration.
+func serve(args []string) error {
+	// Load the configuration from defaults, the config file, the environment and flags
+	appConfig, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("loading configuration: %w", err)
+	}
+	port := fmt.Sprintf(":%d", appConfig.Port)
+	if err := logging.Setup(appConfig.LogFormat, appConfig.LogLevel); err != nil {
+		return fmt.Errorf("setting up logging: %v", err)
+	}
+
+	// Set up tracing before anything creates spans
+	shutdownTracing, err := tracing.Setup(context.Background(), appConfig.TracingExporter, appConfig.TracingEndpoint)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Build the upstream client and pass the configuration to packages that need it
+	upstream := source.NewClient(appConfig.APIKey, upstreamOptions(appConfig))
+	handle.InitConfig(appConfig, upstream)
+
+	// Register metrics with Prometheus
+	metrics.RegisterMetrics()
+
+	// Connect to the database and bring the schema up to date
+	if err := db.Connect(appConfig.DatabaseURL, appConfig.DBMaxConns, appConfig.DBMinConns); err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Migrate(context.Background()); err != nil {
+		return fmt.Errorf("applying migrations: %v", err)
+	}
+	services.UpdateDataMetrics(context.Background())
+
+	// Start background routines to update metrics. They stop when background is cancelled,
+	// which only happens once the server has drained so in-flight requests can still submit jobs.
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	metrics.StartUptime(background, appConfig.AvailabilityFile)
+	metrics.UpdateSystemMetrics(background, appConfig.MetricsInterval)
+	slos := make([]metrics.SLO, 0, len(appConfig.SLOs))
+	for _, s := range appConfig.SLOs {
+		slos = append(slos, metrics.SLO(s))
+	}
+	metrics.StartSLOs(background, slos, appConfig.SLOInterval)
+
+	// Start the job queue and the periodic refresh and snapshot schedules.
+	queue := jobs.NewQueue(appConfig.JobWorkers, appConfig.JobQueueSize)
+	jobs.RegisterDefaults(queue, upstream, exportOptions(appConfig))
+	queue.Start(background)
+	queue.Schedule(background, jobs.KindRefresh, appConfig.RefreshInterval)
+	queue.Schedule(background, jobs.KindExport, appConfig.ExportInterval)
+	handle.InitJobs(queue)
+
+	// Apply safe configuration changes on SIGHUP or when the config or secret files change.
+	reloads := newReloader(args, appConfig, upstream, queue)
+	reloads.Start(background)
+
+	// Liveness checks the process itself (serving, and job workers not stuck); readiness
+	// checks its dependencies. Non-critical checks report a degraded status but keep the pod in rotation.
+	liveness := health.NewChecker(appConfig.HealthCheckTimeout)
+	if appConfig.HealthMaxJobRuntime > 0 {
+		liveness.Register("job_workers", true, health.Workers(queue, appConfig.HealthMaxJobRuntime))
+	}
+	readiness := health.NewChecker(appConfig.HealthCheckTimeout)
+	readiness.Register("database", true, health.Database())
+	readiness.Register("migrations", true, health.Migrations())
+	if appConfig.HealthMaxRefreshAge > 0 {
+		readiness.Register("refresh_age", false, health.RefreshAge(appConfig.HealthMaxRefreshAge))
+	}
+	readiness.Register("upstream_circuit", false, health.Circuit(upstream))
+	handle.InitHealth(liveness, readiness)
+
+	// Instrument and register HTTP handlers with static route patterns.
+	// These static patterns ensure dynamic parts (e.g., email or id) are not included in the metric labels.
+	mux := http.NewServeMux()
+	mux.Handle("/", metrics.InstrumentHandler("/", http.HandlerFunc(handle.FederalFundsHandlerInsight)))
+	mux.Handle("/insights", metrics.InstrumentHandler("/insights", http.HandlerFunc(handle.PeriodInsights)))
+	mux.Handle("/analytics/rolling", metrics.InstrumentHandler("/analytics/rolling", http.HandlerFunc(handle.RollingStatistics)))
+	mux.Handle("/forecast", metrics.InstrumentHandler("/forecast", http.HandlerFunc(handle.Forecast)))
+	mux.Handle("/auth/{email}", metrics.InstrumentHandler("/auth/{email}", http.HandlerFunc(handle.UserInfo)))
+	mux.Handle("/create", metrics.InstrumentHandler("/create", http.HandlerFunc(handle.CreateUser)))
+	mux.Handle("/health", metrics.InstrumentHandler("/health", http.HandlerFunc(handle.HealthCheck)))
+	mux.Handle("/livez", metrics.InstrumentHandler("/livez", http.HandlerFunc(handle.Liveness)))
+	mux.Handle("/readyz", metrics.InstrumentHandler("/readyz", http.HandlerFunc(handle.Readiness)))
+	mux.Handle("/admin/jobs", metrics.InstrumentHandler("/admin/jobs", handle.RequireAdmin(http.HandlerFunc(handle.Jobs))))
+	mux.Handle("/admin/slo", metrics.InstrumentHandler("/admin/slo", handle.RequireAdmin(http.HandlerFunc(handle.SLOs))))
+	mux.Handle("/admin/config", metrics.InstrumentHandler("/admin/config", handle.RequireAdmin(http.HandlerFunc(handle.ConfigDump))))
+	mux.Handle("/admin/dashboard", metrics.InstrumentHandler("/admin/dashboard", handle.RequireAdmin(http.HandlerFunc(handle.Dashboard))))
+
+	// Expose the /metrics endpoint for Prometheus to scrape real-time metrics.
+	mux.Handle("/metrics", metrics.MetricsHandler())
+
+	server := &http.Server{
+		Addr: port,
+		// Assign request IDs before routing so every handler, log line and error response carries one.
+		Handler:           logging.Middleware(mux),
+		ReadTimeout:       appConfig.ReadTimeout,
+		ReadHeaderTimeout: appConfig.ReadHeaderTimeout,
+		WriteTimeout:      appConfig.WriteTimeout,
+		IdleTimeout:       appConfig.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server is running", "addr", port)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The server failed to start or stopped on its own; still clean up below.
+		stopBackground()
+		reloads.Wait()
+		queue.Wait()
+		metrics.Wait()
+		return fmt.Errorf("serving HTTP: %v", err)
+	case <-signals.Done():
+		stopSignals() // A second signal kills the process immediately.
+	}
+
+	slog.Info("Shutting down", "timeout", appConfig.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), appConfig.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("In-flight requests did not finish before the shutdown timeout", "error", err)
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Warn("Server stopped with an error", "error", err)
+	}
+
+	// Stop reloads, the scheduler, metric routines and job workers; workers finish their current job.
+	stopBackground()
+	reloads.Wait()
+	queue.Wait()
+	metrics.Wait()
+	slog.Info("Shutdown complete")
+	return nil
+}
+
+// upstreamOptions returns the upstream client options of a configuration.
+func upstreamOptions(c *config.Config) source.Options {
+	return source.Options{
+		Timeout:          c.UpstreamTimeout,
+		MaxRetries:       c.UpstreamMaxRetries,
+		BaseBackoff:      c.UpstreamBaseBackoff,
+		MaxBackoff:       c.UpstreamMaxBackoff,
+		BreakerThreshold: c.UpstreamBreakerThreshold,
+		BreakerCooldown:  c.UpstreamBreakerCooldown,
+		RateLimit:        c.UpstreamRateLimit,
+	}
+}
+
+// exportOptions returns the snapshot options of a configuration.
+func exportOptions(c *config.Config) export.Options {
+	return export.Options{Dir: c.ExportDir, Format: c.ExportFormat, Keep: c.ExportKeep}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+	"federal-funds-rate-metrics-ByYear/db"
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/tracing"
+
+	"github.com/jackc/pgx/v5"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ObservationsFromResponse converts an Alpha Vantage response into observations,
+// skipping entries with unparseable dates or values.
+func ObservationsFromResponse(data dto.AlphaVantageResponse, source string) []dto.Observation {
+	var observations []dto.Observation
+	for _, record := range data.Data {
+		date, err := time.Parse("2006-01-02", record["date"])
+		if err != nil {
+			slog.Warn("Skipping observation with invalid date", "date", record["date"], "error", err)
+			continue
+		}
+		value, err := strconv.ParseFloat(record["value"], 64)
+		if err != nil {
+			slog.Warn("Skipping observation with invalid rate", "date", record["date"], "value", record["value"], "error", err)
+			continue
+		}
+		observations = append(observations, dto.Observation{Date: date, Value: value, Source: source})
+	}
+	return observations
+}
+
+// ResponseFromObservations converts stored observations back into the Alpha Vantage
+// shape understood by ProcessFederalFundsData.
+func ResponseFromObservations(observations []dto.Observation) dto.AlphaVantageResponse {
+	data := dto.AlphaVantageResponse{Data: make([]map[string]string, 0, len(observations))}
+	for _, o := range observations {
+		data.Data = append(data.Data, map[string]string{
+			"date":  o.Date.Format("2006-01-02"),
+			"value": strconv.FormatFloat(o.Value, 'f', -1, 64),
+		})
+	}
+	return data
+}
+
+// StoreObservations upserts fetched observations into the observations table, all or none
+// of them. Their fetched_at is set to now, which LastRefreshTime reads.
+func StoreObservations(ctx context.Context, observations []dto.Observation) error {
+	query := `INSERT INTO federal_funds_observations (date, value, source, fetched_at)
+	          VALUES ($1, $2, $3, now())
+	          ON CONFLICT (date) DO UPDATE
+	          SET value = EXCLUDED.value,
+	              source = EXCLUDED.source,
+	              fetched_at = EXCLUDED.fetched_at,
+	              imported_at = NULL`
+	return storeObservations(ctx, "observations_upsert", query, observations)
+}
+
+// StoreImportedObservations upserts observations read from files, all or none of them.
+// They get imported_at rather than fetched_at, so an import is not mistaken for a refresh;
+// rows that were fetched before keep their fetched_at.
+func StoreImportedObservations(ctx context.Context, observations []dto.Observation) error {
+	query := `INSERT INTO federal_funds_observations (date, value, source, imported_at)
+	          VALUES ($1, $2, $3, now())
+	          ON CONFLICT (date) DO UPDATE
+	          SET value = EXCLUDED.value,
+	              source = EXCLUDED.source,
+	              imported_at = EXCLUDED.imported_at`
+	return storeObservations(ctx, "observations_import", query, observations)
+}
+
+// storeObservations runs query for each observation in a single batch, so a failure
+// leaves the table unchanged.
+func storeObservations(ctx context.Context, operation, query string, observations []dto.Observation) error {
+	batch := &pgx.Batch{}
+	for _, o := range observations {
+		batch.Queue(query, o.Date, o.Value, o.Source)
+	}
+	if err := db.SendBatch(ctx, operation, batch); err != nil {
+		return fmt.Errorf("failed to store %d observations: %v", len(observations), err)
+	}
+	return nil
+}
+
+// LastRefreshTime returns when observations were last fetched, or the zero time if none
+// were. Imported observations do not count.
+func LastRefreshTime(ctx context.Context) (time.Time, error) {
+	var fetchedAt *time.Time
+	err := db.QueryRow(ctx, "observations_last_fetch", "SELECT MAX(fetched_at) FROM federal_funds_observations").Scan(&fetchedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read last refresh time: %v", err)
+	}
+	if fetchedAt == nil {
+		return time.Time{}, nil
+	}
+	return *fetchedAt, nil
+}
+
+// GetObservations returns all stored observations ordered by date.
+func GetObservations(ctx context.Context) ([]dto.Observation, error) {
+	query := "SELECT date, value, source FROM federal_funds_observations ORDER BY date"
+	rows, err := db.Query(ctx, "observations_list", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve observations: %v", err)
+	}
+	defer rows.Close()
+	var observations []dto.Observation
+	for rows.Next() {
+		var o dto.Observation
+		if err := rows.Scan(&o.Date, &o.Value, &o.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan observation row: %v", err)
+		}
+		observations = append(observations, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %v", err)
+	}
+
+	return observations, nil
+}
+
+// RefreshInsights stores freshly fetched data as observations, then computes and stores insights from it.
+// The outcome is recorded in the refresh metrics.
+func RefreshInsights(ctx context.Context, data dto.AlphaVantageResponse, source string) (insights []dto.YearlyInsight, err error) {
+	defer func() {
+		appmetrics.RecordRefresh(err == nil)
+		if err == nil {
+			UpdateDataMetrics(ctx)
+		}
+	}()
+
+	if err := StoreObservations(ctx, ObservationsFromResponse(data, source)); err != nil {
+		return nil, err
+	}
+
+	insights, err = processTraced(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process data: %v", err)
+	}
+
+	if err := StoreFederalFundsInsights(ctx, insights); err != nil {
+		return nil, fmt.Errorf("failed to store insights: %v", err)
+	}
+	return insights, nil
+}
+
+// UpdateDataMetrics sets the latest-rate and years-stored gauges from the database.
+// Errors are logged rather than returned since the gauges are best effort.
+func UpdateDataMetrics(ctx context.Context) {
+	var date time.Time
+	var value float64
+	query := "SELECT date, value FROM federal_funds_observations ORDER BY date DESC LIMIT 1"
+	err := db.QueryRow(ctx, "observations_latest", query).Scan(&date, &value)
+	if err == nil {
+		appmetrics.SetLatestObservation(date, value)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		slog.ErrorContext(ctx, "Error reading latest observation", "error", err)
+	}
+
+	var years int
+	err = db.QueryRow(ctx, "insights_count", "SELECT COUNT(*) FROM federal_funds_insights").Scan(&years)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error counting stored years", "error", err)
+		return
+	}
+	appmetrics.SetYearsStored(years)
+}
+
+// RecomputeInsights recomputes and stores insights from the stored observations,
+// without contacting the upstream provider.
+func RecomputeInsights(ctx context.Context) ([]dto.YearlyInsight, error) {
+	observations, err := GetObservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("no stored observations to recompute from")
+	}
+
+	insights, err := processTraced(ctx, ResponseFromObservations(observations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to process data: %v", err)
+	}
+
+	if err := StoreFederalFundsInsights(ctx, insights); err != nil {
+		return nil, fmt.Errorf("failed to store insights: %v", err)
+	}
+	UpdateDataMetrics(ctx)
+	return insights, nil
+}
+
+// ImportObservations stores observations read from files, then recomputes and stores the insights
+// of every year from all stored observations, so partial files update only the months they contain.
+// An import is not a refresh: it does not change the refresh time or the refresh metrics.
+func ImportObservations(ctx context.Context, observations []dto.Observation) ([]dto.YearlyInsight, error) {
+	if err := StoreImportedObservations(ctx, observations); err != nil {
+		return nil, err
+	}
+	return RecomputeInsights(ctx)
+}
+
+// processTraced runs ProcessFederalFundsData inside a tracing span.
+func processTraced(ctx context.Context, data dto.AlphaVantageResponse) ([]dto.YearlyInsight, error) {
+	_, span := tracing.Tracer().Start(ctx, "process insights")
+	defer span.End()
+	span.SetAttributes(attribute.Int("observations", len(data.Data)))
+
+	insights, err := ProcessFederalFundsData(data)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("years", len(insights)))
+	return insights, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+// Period kinds accepted by ParsePeriod.
+const (
+	PeriodQuarter = "quarter"
+	PeriodHalf    = "half"
+	PeriodYear    = "year"
+	PeriodDecade  = "decade"
+)
+
+// PeriodKinds lists the period kinds in increasing length.
+var PeriodKinds = []string{PeriodQuarter, PeriodHalf, PeriodYear, PeriodDecade}
+
+// periodMonths is the length of each period kind in months.
+var periodMonths = map[string]int{PeriodQuarter: 3, PeriodHalf: 6, PeriodYear: 12, PeriodDecade: 120}
+
+// Period describes how observations are bucketed: by quarter, half-year, year or decade
+// of a calendar year or of a fiscal year starting in FiscalStartMonth.
+//
+// Fiscal years are named after the calendar year they end in, so with FiscalStartMonth
+// October, FY2025 runs from October 2024 to September 2025 and FY2025-Q1 is
+// October to December 2024. Decades group fiscal years (FY1990 to FY1999 are FY1990s).
+type Period struct {
+	Kind             string
+	FiscalStartMonth time.Month
+}
+
+// ParsePeriod validates a period kind and fiscal year start month (1-12; 0 means January).
+// "half-year" is accepted for "half".
+func ParsePeriod(kind string, fiscalStartMonth int) (Period, error) {
+	kind = strings.ToLower(kind)
+	if kind == "half-year" {
+		kind = PeriodHalf
+	}
+	if _, ok := periodMonths[kind]; !ok {
+		return Period{}, fmt.Errorf("period must be one of %s, got %q", strings.Join(PeriodKinds, ", "), kind)
+	}
+	if fiscalStartMonth == 0 {
+		fiscalStartMonth = 1
+	}
+	if fiscalStartMonth < 1 || fiscalStartMonth > 12 {
+		return Period{}, fmt.Errorf("fiscal start month must be between 1 and 12, got %d", fiscalStartMonth)
+	}
+	return Period{Kind: kind, FiscalStartMonth: time.Month(fiscalStartMonth)}, nil
+}
+
+// fiscal reports whether years start in a month other than January.
+func (p Period) fiscal() bool {
+	return p.FiscalStartMonth > time.January
+}
+
+// bucket returns the label and first day of the period containing date.
+func (p Period) bucket(date time.Time) (string, time.Time) {
+	start := p.FiscalStartMonth
+	if start == 0 {
+		start = time.January
+	}
+	// Fiscal years are named after the calendar year they end in.
+	year := date.Year()
+	if p.fiscal() && date.Month() >= start {
+		year++
+	}
+	prefix := ""
+	yearStartYear := year
+	if p.fiscal() {
+		prefix = "FY"
+		yearStartYear = year - 1
+	}
+	yearStart := time.Date(yearStartYear, start, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(date.Month()) - int(start) + 12) % 12 // Months into the (fiscal) year.
+
+	switch p.Kind {
+	case PeriodQuarter:
+		q := offset / 3
+		return fmt.Sprintf("%s%d-Q%d", prefix, year, q+1), yearStart.AddDate(0, q*3, 0)
+	case PeriodHalf:
+		h := offset / 6
+		return fmt.Sprintf("%s%d-H%d", prefix, year, h+1), yearStart.AddDate(0, h*6, 0)
+	case PeriodDecade:
+		decade := year - ((year%10)+10)%10
+		return fmt.Sprintf("%s%ds", prefix, decade), yearStart.AddDate(decade-year, 0, 0)
+	default:
+		return fmt.Sprintf("%s%d", prefix, year), yearStart
+	}
+}
+
+// periodBucket collects the observations of one period.
+type periodBucket struct {
+	label        string
+	observations []dto.Observation
+}
+
+// Aggregate computes insights per period from monthly observations, newest period first.
+// Growth is measured against the average of the immediately preceding period and is 0
+// when that period has no observations or an average of 0, where growth is undefined.
+func Aggregate(observations []dto.Observation, period Period) []dto.PeriodInsight {
+	buckets := make(map[time.Time]*periodBucket)
+	for _, o := range observations {
+		label, start := period.bucket(o.Date)
+		b, ok := buckets[start]
+		if !ok {
+			b = &periodBucket{label: label}
+			buckets[start] = b
+		}
+		b.observations = append(b.observations, o)
+	}
+
+	months := periodMonths[period.Kind]
+	insights := make([]dto.PeriodInsight, 0, len(buckets))
+	for start, b := range buckets {
+		sort.Slice(b.observations, func(i, j int) bool { return b.observations[i].Date.Before(b.observations[j].Date) })
+		insight := dto.PeriodInsight{
+			Period:       b.label,
+			Start:        start.Format("2006-01-02"),
+			End:          start.AddDate(0, months, -1).Format("2006-01-02"),
+			Observations: len(b.observations),
+			AverageRate:  averageOf(b.observations),
+		}
+		highest, lowest := b.observations[0], b.observations[0]
+		for _, o := range b.observations[1:] {
+			if o.Value > highest.Value {
+				highest = o
+			}
+			if o.Value < lowest.Value {
+				lowest = o
+			}
+		}
+		insight.HighestRate, insight.HighestRateMonth = highest.Value, highest.Date.Format("2006-01")
+		insight.LowestRate, insight.LowestRateMonth = lowest.Value, lowest.Date.Format("2006-01")
+
+		if previous, ok := buckets[start.AddDate(0, -months, 0)]; ok {
+			if previousAverage := averageOf(previous.observations); previousAverage != 0 {
+				insight.GrowthPercentage = ((insight.AverageRate - previousAverage) / previousAverage) * 100
+			}
+		}
+		insights = append(insights, insight)
+	}
+
+	sort.Slice(insights, func(i, j int) bool { return insights[i].Start > insights[j].Start })
+	return insights
+}
+
+func averageOf(observations []dto.Observation) float64 {
+	rates := make([]float64, len(observations))
+	for i, o := range observations {
+		rates[i] = o.Value
+	}
+	return calculateAverage(rates)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/dto"
+)
+
+func date(year int, month time.Month) time.Time {
+	return time.Date(year, month, 15, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPeriodBucket(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    Period
+		date      time.Time
+		wantLabel string
+		wantStart string
+	}{
+		// Calendar years.
+		{"calendar Q1", Period{Kind: PeriodQuarter, FiscalStartMonth: time.January}, date(2024, time.March), "2024-Q1", "2024-01-01"},
+		{"calendar Q2", Period{Kind: PeriodQuarter, FiscalStartMonth: time.January}, date(2024, time.April), "2024-Q2", "2024-04-01"},
+		{"calendar Q4", Period{Kind: PeriodQuarter, FiscalStartMonth: time.January}, date(2024, time.December), "2024-Q4", "2024-10-01"},
+		{"calendar H1", Period{Kind: PeriodHalf, FiscalStartMonth: time.January}, date(2024, time.June), "2024-H1", "2024-01-01"},
+		{"calendar H2", Period{Kind: PeriodHalf, FiscalStartMonth: time.January}, date(2024, time.July), "2024-H2", "2024-07-01"},
+		{"calendar year", Period{Kind: PeriodYear, FiscalStartMonth: time.January}, date(2024, time.December), "2024", "2024-01-01"},
+		{"unset start month is January", Period{Kind: PeriodYear}, date(2024, time.January), "2024", "2024-01-01"},
+
+		// Fiscal years starting in October are named after the year they end in.
+		{"fiscal September ends the year", Period{Kind: PeriodYear, FiscalStartMonth: time.October}, date(2024, time.September), "FY2024", "2023-10-01"},
+		{"fiscal October starts the next year", Period{Kind: PeriodYear, FiscalStartMonth: time.October}, date(2024, time.October), "FY2025", "2024-10-01"},
+		{"fiscal September is Q4", Period{Kind: PeriodQuarter, FiscalStartMonth: time.October}, date(2024, time.September), "FY2024-Q4", "2024-07-01"},
+		{"fiscal October is Q1", Period{Kind: PeriodQuarter, FiscalStartMonth: time.October}, date(2024, time.October), "FY2025-Q1", "2024-10-01"},
+		{"fiscal December is still Q1", Period{Kind: PeriodQuarter, FiscalStartMonth: time.October}, date(2024, time.December), "FY2025-Q1", "2024-10-01"},
+		{"fiscal January is Q2", Period{Kind: PeriodQuarter, FiscalStartMonth: time.October}, date(2025, time.January), "FY2025-Q2", "2025-01-01"},
+		{"fiscal March is H1", Period{Kind: PeriodHalf, FiscalStartMonth: time.October}, date(2025, time.March), "FY2025-H1", "2024-10-01"},
+		{"fiscal April is H2", Period{Kind: PeriodHalf, FiscalStartMonth: time.October}, date(2025, time.April), "FY2025-H2", "2025-04-01"},
+		{"fiscal year starting in December", Period{Kind: PeriodYear, FiscalStartMonth: time.December}, date(2024, time.December), "FY2025", "2024-12-01"},
+
+		// Decades.
+		{"last year of a decade", Period{Kind: PeriodDecade, FiscalStartMonth: time.January}, date(1999, time.December), "1990s", "1990-01-01"},
+		{"first year of a decade", Period{Kind: PeriodDecade, FiscalStartMonth: time.January}, date(2000, time.January), "2000s", "2000-01-01"},
+		{"fiscal decade before the boundary", Period{Kind: PeriodDecade, FiscalStartMonth: time.October}, date(1999, time.September), "FY1990s", "1989-10-01"},
+		{"fiscal decade after the boundary", Period{Kind: PeriodDecade, FiscalStartMonth: time.October}, date(1999, time.October), "FY2000s", "1999-10-01"},
+		{"fiscal decade end", Period{Kind: PeriodDecade, FiscalStartMonth: time.October}, date(2009, time.September), "FY2000s", "1999-10-01"},
+		{"fiscal July decade", Period{Kind: PeriodDecade, FiscalStartMonth: time.July}, date(2000, time.June), "FY2000s", "1999-07-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, start := tt.period.bucket(tt.date)
+			if label != tt.wantLabel || start.Format("2006-01-02") != tt.wantStart {
+				t.Errorf("bucket(%s) = %s starting %s, want %s starting %s",
+					tt.date.Format("2006-01"), label, start.Format("2006-01-02"), tt.wantLabel, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestAggregateGrowth(t *testing.T) {
+	observation := func(year int, month time.Month, value float64) dto.Observation {
+		return dto.Observation{Date: time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), Value: value}
+	}
+	tests := []struct {
+		name         string
+		observations []dto.Observation
+		want         map[string]float64 // Growth by period label.
+	}{
+		{
+			name:         "against the preceding year",
+			observations: []dto.Observation{observation(2022, time.June, 2), observation(2023, time.June, 3)},
+			want:         map[string]float64{"2023": 50, "2022": 0},
+		},
+		{
+			name:         "0 after a gap",
+			observations: []dto.Observation{observation(2021, time.June, 2), observation(2023, time.June, 3)},
+			want:         map[string]float64{"2023": 0, "2021": 0},
+		},
+		{
+			name:         "0 after a zero average",
+			observations: []dto.Observation{observation(2022, time.June, 0), observation(2023, time.June, 0.25)},
+			want:         map[string]float64{"2023": 0, "2022": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insights := Aggregate(tt.observations, Period{Kind: PeriodYear, FiscalStartMonth: time.January})
+			if len(insights) != len(tt.want) {
+				t.Fatalf("Aggregate() returned %d periods, want %d", len(insights), len(tt.want))
+			}
+			for _, insight := range insights {
+				if want := tt.want[insight.Period]; insight.GrowthPercentage != want {
+					t.Errorf("growth of %s = %g, want %g", insight.Period, insight.GrowthPercentage, want)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,7 @@ package services
 
 import (
 	"federal-funds-rate-metrics-ByYear/dto"
-	"federal-funds-rate-metrics-ByYear/metrics"
-	"log"
+	"log/slog"
 	"strconv"
 	"time"
 
@@ -12,25 +11,19 @@ import (
 	"federal-funds-rate-metrics-ByYear/db"
 )
 
-func IsCurrentYearDataPresent(year int) (bool, error) {
-	// Start the timer for query execution
-	start := time.Now()
+func IsCurrentYearDataPresent(ctx context.Context, year int) (bool, error) {
 	query := `SELECT EXISTS (
 		SELECT 1 FROM federal_funds_insights WHERE year = $1
 	)`
 	var exists bool
-	err := db.Conn.QueryRow(context.Background(), query, year).Scan(&exists)
+	err := db.QueryRow(ctx, "insights_exists", query, year).Scan(&exists)
 	if err != nil {
 		return false, err
 	}
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
 	return exists, nil
 }
 
-func GetAllYearsData() ([]dto.YearlyInsight, error) {
-	// Start the timer for query execution
-	start := time.Now()
+func GetAllYearsData(ctx context.Context) ([]dto.YearlyInsight, error) {
 	query := `SELECT year, average_rate, highest_rate, lowest_rate, growth_percentage, highest_rate_month, lowest_rate_month
 				FROM federal_funds_insights
 				ORDER BY 
@@ -38,13 +31,11 @@ func GetAllYearsData() ([]dto.YearlyInsight, error) {
 `
 
 	// Execute the query and get a rows iterator
-	rows, err := db.Conn.Query(context.Background(), query)
+	rows, err := db.Query(ctx, "insights_list", query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close() // Ensure rows are closed after iteration
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
 	var insights []dto.YearlyInsight
 
 	// Iterate through the result set
@@ -73,66 +64,52 @@ func GetAllYearsData() ([]dto.YearlyInsight, error) {
 	return insights, nil
 }
 
+// ProcessFederalFundsData computes insights per calendar year from Alpha Vantage data.
+// It is Aggregate with a calendar-year Period, giving months as MM.
 func ProcessFederalFundsData(data dto.AlphaVantageResponse) ([]dto.YearlyInsight, error) {
-	yearlyData := make(map[int][]float64)
-	monthlyRates := make(map[int]map[string]float64)
-
-	// Organize data by year and month
+	// Organize data into observations, skipping records without a valid month or rate
+	observations := make([]dto.Observation, 0, len(data.Data))
 	for _, record := range data.Data {
 		date := record["date"]
 		rateStr := record["value"]
 
 		rate, err := strconv.ParseFloat(rateStr, 64)
 		if err != nil {
-			log.Printf("Error parsing rate: %v\n", err)
+			slog.Warn("Skipping record with invalid rate", "date", date, "value", rateStr, "error", err)
 			continue
 		}
 
-		year, month := parseYearMonth(date)
-		if year != 0 {
-			yearlyData[year] = append(yearlyData[year], rate)
-
-			if monthlyRates[year] == nil {
-				monthlyRates[year] = make(map[string]float64)
-			}
-			monthlyRates[year][month] = rate
+		month, ok := parseMonth(date)
+		if ok {
+			observations = append(observations, dto.Observation{Date: month, Value: rate})
 		}
 	}
 
 	// Calculate insights
 	var insights []dto.YearlyInsight
-	for year, rates := range yearlyData {
-		averageRate := calculateAverage(rates)
-		highestRate, lowestRate, highestMonth, lowestMonth := calculateExtremes(monthlyRates[year])
-
-		var growthPercentage float64
-		if prevRates, ok := yearlyData[year-1]; ok {
-			lastYearAvg := calculateAverage(prevRates)
-			growthPercentage = ((averageRate - lastYearAvg) / lastYearAvg) * 100
-		}
-
-		insight := dto.YearlyInsight{
+	for _, p := range Aggregate(observations, Period{Kind: PeriodYear}) {
+		year, _ := strconv.Atoi(p.Period)
+		insights = append(insights, dto.YearlyInsight{
 			Year:             year,
-			AverageRate:      averageRate,
-			HighestRate:      highestRate,
-			LowestRate:       lowestRate,
-			GrowthPercentage: growthPercentage,
-			HighestRateMonth: highestMonth,
-			LowestRateMonth:  lowestMonth,
-		}
-		insights = append(insights, insight)
+			AverageRate:      p.AverageRate,
+			HighestRate:      p.HighestRate,
+			LowestRate:       p.LowestRate,
+			GrowthPercentage: p.GrowthPercentage,
+			HighestRateMonth: p.HighestRateMonth[5:],
+			LowestRateMonth:  p.LowestRateMonth[5:],
+		})
 	}
 
 	return insights, nil
 }
 
-func parseYearMonth(date string) (int, string) {
+// parseMonth returns the first day of the month of a YYYY-MM or YYYY-MM-DD date.
+func parseMonth(date string) (time.Time, bool) {
 	if len(date) < 7 {
-		return 0, ""
+		return time.Time{}, false
 	}
-	year, _ := strconv.Atoi(date[:4])
-	month := date[5:7]
-	return year, month
+	month, err := time.Parse("2006-01", date[:7])
+	return month, err == nil
 }
 
 func calculateAverage(rates []float64) float64 {
@@ -143,27 +120,7 @@ func calculateAverage(rates []float64) float64 {
 	return sum / float64(len(rates))
 }
 
-func calculateExtremes(monthlyRates map[string]float64) (float64, float64, string, string) {
-	var highestRate, lowestRate float64 = -1e9, 1e9
-	var highestMonth, lowestMonth string
-
-	for month, rate := range monthlyRates {
-		if rate > highestRate {
-			highestRate = rate
-			highestMonth = month
-		}
-		if rate < lowestRate {
-			lowestRate = rate
-			lowestMonth = month
-		}
-	}
-
-	return highestRate, lowestRate, highestMonth, lowestMonth
-}
-
-func StoreFederalFundsInsights(insights []dto.YearlyInsight) error {
-	// Start the timer for query execution
-	start := time.Now()
+func StoreFederalFundsInsights(ctx context.Context, insights []dto.YearlyInsight) error {
 	query := `INSERT INTO federal_funds_insights (year, average_rate, highest_rate, lowest_rate, growth_percentage, highest_rate_month, lowest_rate_month)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7)
 	          ON CONFLICT (year) DO UPDATE
@@ -175,16 +132,13 @@ func StoreFederalFundsInsights(insights []dto.YearlyInsight) error {
 	              lowest_rate_month = EXCLUDED.lowest_rate_month`
 
 	for _, insight := range insights {
-		_, err := db.Conn.Exec(context.Background(), query,
+		_, err := db.Exec(ctx, "insights_upsert", query,
 			insight.Year, insight.AverageRate, insight.HighestRate, insight.LowestRate, insight.GrowthPercentage, insight.HighestRateMonth, insight.LowestRateMonth)
 		if err != nil {
-			log.Printf("Failed to insert insight for year %d: %v\n", insight.Year, err)
+			slog.ErrorContext(ctx, "Failed to insert insight", "year", insight.Year, "error", err)
 			return err
 		}
 	}
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
-	
-	log.Println("Insights stored successfully.")
+	slog.InfoContext(ctx, "Insights stored successfully.", "years", len(insights))
 	return nil
 }
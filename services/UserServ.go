@@ -2,28 +2,27 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"federal-funds-rate-metrics-ByYear/db"
 	"federal-funds-rate-metrics-ByYear/dto"
-	"federal-funds-rate-metrics-ByYear/metrics"
 	"federal-funds-rate-metrics-ByYear/models"
 	"fmt"
-	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// uniqueViolation is the PostgreSQL SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
 // GetAllUsers retrieves all users from the database
-func GetAllUsers() ([]models.User, error) {
-	// Start the timer for query execution
-	start := time.Now()
+func GetAllUsers(ctx context.Context) ([]models.User, error) {
 	query := "SELECT id, name, email FROM users"
-	rows, err := db.Conn.Query(context.Background(), query)
+	rows, err := db.Query(ctx, "users_list", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve users: %v", err)
 	}
 	defer rows.Close()
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
-	
 	var users []models.User
 	for rows.Next() {
 		var user models.User
@@ -40,41 +39,52 @@ func GetAllUsers() ([]models.User, error) {
 	return users, nil
 }
 
-// GetUserByID retrieves a user by ID from the database
-func GetUserByEmail(email string) (models.User, error) {
-	// Start the timer for query execution
-	start := time.Now()
+// GetUserByEmail retrieves a user by email from the database.
+// It returns an error wrapping ErrNotFound if there is no such user.
+func GetUserByEmail(ctx context.Context, email string) (models.User, error) {
 	query := "SELECT id, name, email FROM users WHERE email = $1"
 	var user models.User
 
-	err := db.Conn.QueryRow(context.Background(), query, email).Scan(&user.ID, &user.Name, &user.Email)
+	err := db.QueryRow(ctx, "user_by_email", query, email).Scan(&user.ID, &user.Name, &user.Email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return models.User{}, nil // Return an empty user if not found
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, fmt.Errorf("%w: no user with email %s", ErrNotFound, email)
 		}
 		return models.User{}, fmt.Errorf("failed to retrieve user: %v", err)
 	}
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
-
 	return user, nil
 }
 
-// CreateUser adds a new user to the database and returns the created user
-func CreateUser(requestDto *dto.UserDto) (*dto.UserDto, error) {
-	// Start the timer for query execution
-	start := time.Now()
+// CreateUser adds a new user to the database and returns the created user.
+// It returns an error wrapping ErrConflict if the email is already registered.
+func CreateUser(ctx context.Context, requestDto *dto.UserDto) (*dto.UserDto, error) {
 	query := "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"
 	var newUserID int
 
-	err := db.Conn.QueryRow(context.Background(), query, requestDto.Name, requestDto.Email).Scan(&newUserID)
+	err := db.QueryRow(ctx, "user_create", query, requestDto.Name, requestDto.Email).Scan(&newUserID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, fmt.Errorf("%w: a user with email %s already exists", ErrConflict, requestDto.Email)
+		}
 		return nil, fmt.Errorf("failed to create user: %v", err)
 	}
-	// Record the query duration metric
-	metrics.RecordDBQuery(time.Since(start))
 	return &dto.UserDto{
 		Name:  requestDto.Name,
 		Email: requestDto.Email,
 	}, nil
 }
+
+// DeleteUser removes the user with the given email.
+// It returns an error wrapping ErrNotFound if there is no such user.
+func DeleteUser(ctx context.Context, email string) error {
+	query := "DELETE FROM users WHERE email = $1"
+	tag, err := db.Exec(ctx, "user_delete", query, email)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: no user with email %s", ErrNotFound, email)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package services
+
+import "errors"
+
+// Domain errors returned (wrapped) by the services. Handlers map them to HTTP statuses.
+var (
+	// ErrNotFound is returned when a requested record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a record would violate a uniqueness constraint.
+	ErrConflict = errors.New("conflict")
+)
@@ -0,0 +1,131 @@
+package source
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the breaker is open and calls to the provider are being skipped.
+var ErrCircuitOpen = errors.New("upstream circuit breaker is open")
+
+// BreakerState describes the current state of a circuit breaker.
+type BreakerState int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed BreakerState = iota
+	// StateOpen rejects calls until the cooldown has elapsed.
+	StateOpen
+	// StateHalfOpen lets a single trial call through to probe the provider.
+	StateHalfOpen
+)
+
+// String returns a lowercase name for the state.
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a simple consecutive-failure circuit breaker.
+// After threshold consecutive failures it opens for cooldown, then allows one trial call.
+type Breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	cooldown    time.Duration
+	state       BreakerState
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+}
+
+// NewBreaker creates a breaker that opens after threshold consecutive failures.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// SetLimits changes the failure threshold and cooldown without resetting the current state.
+func (b *Breaker) SetLimits(threshold int, cooldown time.Duration) {
+	if threshold < 1 {
+		threshold = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold = threshold
+	b.cooldown = cooldown
+}
+
+// Allow reports whether a call may proceed, returning ErrCircuitOpen if not.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		// Cooldown elapsed: let a single trial call through.
+		b.state = StateHalfOpen
+		b.trialActive = true
+		return nil
+	case StateHalfOpen:
+		if b.trialActive {
+			return ErrCircuitOpen
+		}
+		b.trialActive = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the breaker.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = StateClosed
+	b.failures = 0
+	b.trialActive = false
+}
+
+// Failure records a failed call, opening the breaker once the threshold is reached
+// or immediately if the failed call was a half-open trial.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+	b.trialActive = false
+}
+
+// Abandon records a call that ended without an answer from the provider, e.g. because
+// the caller cancelled it. It neither counts as a failure nor closes the breaker, but
+// frees the half-open trial slot for the next call.
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialActive = false
+}
+
+// State returns the current breaker state.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,133 @@
+package source
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Breaker operations used by the test steps.
+const (
+	opAllow   = "allow"  // Allow must succeed.
+	opReject  = "reject" // Allow must return ErrCircuitOpen.
+	opSuccess = "success"
+	opFailure = "failure"
+	opAbandon = "abandon"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		cooldown  time.Duration
+		steps     []string
+		want      BreakerState
+	}{
+		{
+			name:      "starts closed",
+			threshold: 3, cooldown: time.Hour,
+			steps: []string{opAllow},
+			want:  StateClosed,
+		},
+		{
+			name:      "stays closed below the threshold",
+			threshold: 3, cooldown: time.Hour,
+			steps: []string{opAllow, opFailure, opAllow, opFailure, opAllow},
+			want:  StateClosed,
+		},
+		{
+			name:      "opens at the threshold and rejects calls",
+			threshold: 2, cooldown: time.Hour,
+			steps: []string{opFailure, opFailure, opReject, opReject},
+			want:  StateOpen,
+		},
+		{
+			name:      "success resets the failure count",
+			threshold: 2, cooldown: time.Hour,
+			steps: []string{opFailure, opSuccess, opFailure, opAllow},
+			want:  StateClosed,
+		},
+		{
+			name:      "threshold below 1 opens on the first failure",
+			threshold: 0, cooldown: time.Hour,
+			steps: []string{opFailure, opReject},
+			want:  StateOpen,
+		},
+		{
+			name:      "half-open after the cooldown",
+			threshold: 1, cooldown: 0,
+			steps: []string{opFailure},
+			want:  StateHalfOpen,
+		},
+		{
+			name:      "half-open lets a single trial through",
+			threshold: 1, cooldown: 0,
+			steps: []string{opFailure, opAllow, opReject},
+			want:  StateHalfOpen,
+		},
+		{
+			name:      "successful trial closes",
+			threshold: 1, cooldown: 0,
+			steps: []string{opFailure, opAllow, opSuccess, opAllow, opAllow},
+			want:  StateClosed,
+		},
+		{
+			name:      "abandoned trial frees the slot without closing",
+			threshold: 1, cooldown: 0,
+			steps: []string{opFailure, opAllow, opAbandon, opAllow, opReject},
+			want:  StateHalfOpen,
+		},
+		{
+			name:      "abandon does not count as a failure",
+			threshold: 2, cooldown: time.Hour,
+			steps: []string{opFailure, opAbandon, opAbandon, opAllow},
+			want:  StateClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(tt.threshold, tt.cooldown)
+			for i, step := range tt.steps {
+				switch step {
+				case opAllow:
+					if err := b.Allow(); err != nil {
+						t.Fatalf("step %d: Allow() = %v, want nil", i, err)
+					}
+				case opReject:
+					if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+						t.Fatalf("step %d: Allow() = %v, want ErrCircuitOpen", i, err)
+					}
+				case opSuccess:
+					b.Success()
+				case opFailure:
+					b.Failure()
+				case opAbandon:
+					b.Abandon()
+				}
+			}
+			if got := b.State(); got != tt.want {
+				t.Errorf("State() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerFailedTrialReopens(t *testing.T) {
+	b := NewBreaker(3, 0)
+	for range 3 {
+		b.Failure()
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after cooldown = %v, want nil", err)
+	}
+	// A failed trial reopens at once, whatever the threshold; a long cooldown keeps it open.
+	b.SetLimits(3, time.Hour)
+	b.Failure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %s, want open", got)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() = %v, want ErrCircuitOpen", err)
+	}
+}
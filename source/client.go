@@ -0,0 +1,264 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"federal-funds-rate-metrics-ByYear/appmetrics"
+	"federal-funds-rate-metrics-ByYear/dto"
+	"federal-funds-rate-metrics-ByYear/redact"
+	"federal-funds-rate-metrics-ByYear/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const alphaVantageURL = "https://www.alphavantage.co/query?function=FEDERAL_FUNDS_RATE&interval=monthly&apikey=%s"
+
+// Name identifies this source in metrics labels.
+const Name = "alphavantage"
+
+// ErrUnavailable wraps every error returned by FetchFederalFundsRate, so callers can
+// tell a failing data source apart from their own errors.
+var ErrUnavailable = errors.New("data source unavailable")
+
+// Options configures the upstream HTTP client.
+type Options struct {
+	Timeout          time.Duration // Timeout for a single HTTP attempt.
+	MaxRetries       int           // Number of retries after the first attempt on transient failures.
+	BaseBackoff      time.Duration // Initial backoff before the first retry.
+	MaxBackoff       time.Duration // Upper bound for a single backoff.
+	BreakerThreshold int           // Consecutive failed fetches before the breaker opens.
+	BreakerCooldown  time.Duration // How long the breaker stays open before a trial call.
+	RateLimit        int           // Requests a minute, including retries; 0 for no limit.
+}
+
+// Client fetches federal funds rate data from Alpha Vantage with timeouts, a rate limit,
+// exponential backoff with jitter on transient failures and a circuit breaker.
+// The API key and options can be changed while fetches are in progress;
+// a fetch keeps the settings it started with.
+type Client struct {
+	mu       sync.RWMutex
+	settings clientSettings
+	breaker  *Breaker
+	limiter  *Limiter
+}
+
+// clientSettings is the part of a Client that can be changed at runtime.
+type clientSettings struct {
+	httpClient  *http.Client
+	apiKey      string
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+// NewClient creates a Client for the given API key and options.
+func NewClient(apiKey string, opts Options) *Client {
+	c := &Client{breaker: NewBreaker(opts.BreakerThreshold, opts.BreakerCooldown), limiter: NewLimiter(opts.RateLimit)}
+	c.settings = newSettings(apiKey, opts)
+	return c
+}
+
+func newSettings(apiKey string, opts Options) clientSettings {
+	return clientSettings{
+		httpClient:  &http.Client{Timeout: opts.Timeout},
+		apiKey:      apiKey,
+		maxRetries:  opts.MaxRetries,
+		baseBackoff: opts.BaseBackoff,
+		maxBackoff:  opts.MaxBackoff,
+	}
+}
+
+// SetAPIKey replaces the API key used by subsequent fetches, e.g. after a key rotation.
+func (c *Client) SetAPIKey(apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.settings.apiKey = apiKey
+}
+
+// SetOptions replaces the timeout, retry, rate limit and breaker settings used by
+// subsequent fetches. The breaker keeps its current state.
+func (c *Client) SetOptions(opts Options) {
+	c.mu.Lock()
+	c.settings = newSettings(c.settings.apiKey, opts)
+	c.mu.Unlock()
+	c.breaker.SetLimits(opts.BreakerThreshold, opts.BreakerCooldown)
+	c.limiter.SetRate(opts.RateLimit)
+}
+
+// snapshot returns the current settings.
+func (c *Client) snapshot() clientSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings
+}
+
+// BreakerState returns the current state of the client's circuit breaker.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// FetchFederalFundsRate fetches monthly federal funds rate data, retrying transient failures.
+// It returns ErrCircuitOpen without calling the provider while the breaker is open.
+// Errors wrap ErrUnavailable as well as the underlying cause.
+func (c *Client) FetchFederalFundsRate(ctx context.Context) (dto.AlphaVantageResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "fetch "+Name)
+	defer span.End()
+
+	if err := c.breaker.Allow(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return dto.AlphaVantageResponse{}, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	settings := c.snapshot()
+	var lastErr error
+	for attempt := 0; attempt <= settings.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, settings.backoff(attempt)); err != nil {
+				lastErr = err
+				break
+			}
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			lastErr = err
+			break
+		}
+		data, err := settings.fetchOnce(ctx, attempt)
+		if err == nil {
+			c.breaker.Success()
+			appmetrics.RecordUpstreamObservations(Name, len(data.Data))
+			span.SetAttributes(attribute.Int("observations", len(data.Data)))
+			return data, nil
+		}
+		lastErr = err
+		if !isTransient(err) {
+			break
+		}
+	}
+
+	if errors.Is(lastErr, context.Canceled) || ctx.Err() != nil {
+		// The caller gave up, which says nothing about the provider.
+		c.breaker.Abandon()
+	} else {
+		c.breaker.Failure()
+	}
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+	return dto.AlphaVantageResponse{}, fmt.Errorf("%w: %w", ErrUnavailable, lastErr)
+}
+
+// fetchOnce performs a single request against the provider inside a client span.
+func (c clientSettings) fetchOnce(ctx context.Context, attempt int) (data dto.AlphaVantageResponse, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "GET "+Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("attempt", attempt)),
+	)
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(alphaVantageURL, c.apiKey), nil)
+	if err != nil {
+		return dto.AlphaVantageResponse{}, fmt.Errorf("failed to build request: %v", redact.Error(err))
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		appmetrics.RecordUpstreamRequest(ctx, Name, start, 0, 0)
+		// Transport errors include the request URL, which carries the API key.
+		return dto.AlphaVantageResponse{}, &transientError{fmt.Errorf("failed to fetch data: %v", redact.Error(err))}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	appmetrics.RecordUpstreamRequest(ctx, Name, start, resp.StatusCode, len(body))
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			appmetrics.RecordUpstreamThrottle(Name)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return dto.AlphaVantageResponse{}, &transientError{err}
+		}
+		return dto.AlphaVantageResponse{}, err
+	}
+
+	if err != nil {
+		return dto.AlphaVantageResponse{}, &transientError{fmt.Errorf("failed to read response body: %v", err)}
+	}
+
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return dto.AlphaVantageResponse{}, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	// Alpha Vantage answers with a 200 and a message instead of data. A Note is a per-minute
+	// rate limit worth retrying; an Information message is also used for invalid or missing
+	// API keys and exhausted daily quotas, which retrying does not fix.
+	if len(data.Data) == 0 {
+		switch {
+		case data.Note != "":
+			appmetrics.RecordUpstreamThrottle(Name)
+			return dto.AlphaVantageResponse{}, &transientError{fmt.Errorf("rate limited by provider: %s", data.Note)}
+		case data.Information != "":
+			return dto.AlphaVantageResponse{}, fmt.Errorf("rejected by provider: %s", redact.String(data.Information))
+		}
+	}
+
+	return data, nil
+}
+
+// backoff returns a full-jitter exponential backoff for the given retry attempt (1-based).
+func (c clientSettings) backoff(attempt int) time.Duration {
+	ceiling := c.baseBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > c.maxBackoff {
+		ceiling = c.maxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transientError marks a failure that is worth retrying.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// isTransient reports whether err is worth retrying.
+func isTransient(err error) bool {
+	var t *transientError
+	return errors.As(err, &t)
+}
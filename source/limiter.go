@@ -0,0 +1,51 @@
+package source
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces requests evenly so that at most perMinute of them start in any minute.
+// A limit of 0 lets every request through.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Time between two requests, 0 when unlimited.
+	next     time.Time     // Earliest start of the next request.
+}
+
+// NewLimiter creates a limiter allowing perMinute requests a minute.
+func NewLimiter(perMinute int) *Limiter {
+	l := &Limiter{}
+	l.SetRate(perMinute)
+	return l
+}
+
+// SetRate changes the number of requests allowed a minute. Requests already waiting
+// keep the slot they were given.
+func (l *Limiter) SetRate(perMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = 0
+	if perMinute > 0 {
+		l.interval = time.Minute / time.Duration(perMinute)
+	}
+}
+
+// Wait blocks until the next request may start or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.interval == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, start.Sub(now))
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacing(t *testing.T) {
+	tests := []struct {
+		name      string
+		perMinute int
+		requests  int
+		wantNext  time.Duration // Delay the next request would get, relative to now.
+	}{
+		{name: "unlimited", perMinute: 0, requests: 3, wantNext: 0},
+		{name: "first request is immediate", perMinute: 60, requests: 0, wantNext: 0},
+		{name: "one slot taken", perMinute: 60, requests: 1, wantNext: time.Second},
+		{name: "two slots taken", perMinute: 30, requests: 2, wantNext: 4 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.perMinute)
+			// Reserve slots without waiting for them.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for i := 0; i < tt.requests; i++ {
+				l.Wait(ctx)
+			}
+			var got time.Duration
+			if !l.next.IsZero() {
+				got = time.Until(l.next)
+			}
+			if got > tt.wantNext || got < tt.wantNext-time.Second/2 {
+				t.Errorf("next request in %s, want about %s", got, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestLimiterSetRate(t *testing.T) {
+	l := NewLimiter(1)
+	l.Wait(context.Background())
+	l.SetRate(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != nil {
+		t.Errorf("Wait() after removing the limit: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is reported as service.name on every span.
+const ServiceName = "federal-funds-rate-metrics"
+
+// Exporter names accepted by Setup.
+const (
+	ExporterOTLP   = "otlp"   // OTLP over HTTP to a collector.
+	ExporterStdout = "stdout" // Pretty-printed spans on stdout.
+	ExporterLocal  = "local"  // Spans kept in-process for log and exemplar trace IDs, not exported.
+	ExporterNone   = "none"   // Tracing disabled.
+)
+
+// Tracer returns the application tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(ServiceName)
+}
+
+// Setup installs the global tracer provider and W3C trace context propagator.
+// An empty exporter selects OTLP when endpoint (or OTEL_EXPORTER_OTLP_ENDPOINT) is set and
+// local otherwise, so logs and exemplars carry trace IDs without a collector; stdout has to
+// be asked for, as it mixes multi-line spans into the application logs.
+// The endpoint is a collector URL such as http://localhost:4318; when empty the
+// standard OTEL_EXPORTER_OTLP_* environment variables apply.
+// The returned function flushes and shuts down the provider.
+func Setup(ctx context.Context, exporter, endpoint string) (func(context.Context) error, error) {
+	if exporter == "" {
+		exporter = ExporterLocal
+		if endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+			exporter = ExporterOTLP
+		}
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterLocal:
+		// No exporter: spans are still sampled, so they have trace IDs.
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %v", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %v", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if spanExporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(spanExporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	slog.InfoContext(ctx, "Tracing enabled", "exporter", exporter, "endpoint", endpoint)
+	return provider.Shutdown, nil
+}
+
+// TraceID returns the sampled trace ID in ctx, or "" if there is none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return ""
+	}
+	return sc.TraceID().String()
+}